@@ -0,0 +1,183 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BatchRunner runs several requests, in parallel when asked, printing the responses in
+// the order the requests were given followed by a summary.
+type BatchRunner struct {
+	executor *Executor
+	output   *ResponsePrinter
+	writer   io.Writer
+	parallel int
+}
+
+// batchJob is a request to run, with the variables of a dataset row if any.
+type batchJob struct {
+	title     string
+	name      string
+	variables map[interface{}]interface{}
+	response  *Response
+	err       error
+	done      chan struct{}
+}
+
+func NewBatchRunner(executor *Executor, output *ResponsePrinter, writer io.Writer, parallel int) *BatchRunner {
+	if parallel < 1 {
+		parallel = 1
+	}
+	return &BatchRunner{executor: executor, output: output, writer: writer, parallel: parallel}
+}
+
+// SelectRequests tells which requests run should execute. When the first arg is a request or
+// endpoint name the others are the values of its variables. The args are request names and
+// patterns like 'get_*', the name of a request with a matrix selecting all its combinations,
+// only with --batch or when the first arg is a pattern or a matrix.
+func (executor *Executor) SelectRequests(args []string, all bool, several bool) (names []string, batch bool, err error) {
+	if all {
+		if len(args) > 0 {
+			return nil, false, errors.New("Request names can't be given with --all")
+		}
+		for name := range executor.conf.Requests {
+			names = append(names, name)
+		}
+		if len(names) == 0 {
+			return nil, false, errors.New("No requests found")
+		}
+		sort.Strings(names)
+		return names, true, nil
+	}
+	if len(args) == 0 {
+		return args, false, nil
+	}
+	if !several && (executor.isRequestOrEndpoint(args[0]) ||
+		!isRequestPattern(args[0]) && len(executor.conf.MatrixRequests(args[0])) == 0) {
+		return args[:1], false, nil
+	}
+
+	seen := make(map[string]bool)
+	for _, arg := range args {
+		var matched []string
+		if executor.isRequestOrEndpoint(arg) {
+			matched = []string{arg}
+		} else if variants := executor.conf.MatrixRequests(arg); len(variants) > 0 {
+			matched = variants
+		} else if isRequestPattern(arg) {
+			matched = executor.matchRequests(arg)
+		}
+		if len(matched) == 0 {
+			return nil, false, errors.New(fmt.Sprintf("No request matches '%v'", arg))
+		}
+		for _, name := range matched {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	return names, true, nil
+}
+
+func (executor *Executor) isRequestOrEndpoint(name string) bool {
+	return executor.conf.Requests[name] != nil || executor.conf.Endpoints[name] != nil
+}
+
+func isRequestPattern(arg string) bool {
+	return strings.ContainsAny(arg, "*?[")
+}
+
+// matchRequests returns the sorted names of the requests matching a pattern.
+func (executor *Executor) matchRequests(pattern string) []string {
+	var names []string
+	for name := range executor.conf.Requests {
+		if matched, _ := path.Match(pattern, name); matched {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Run executes the requests and returns how many of them failed, either because they
+// couldn't run or because they got a 4xx or 5xx status. A request with a data attribute
+// runs once per row.
+func (runner *BatchRunner) Run(names []string) int {
+	var jobs []*batchJob
+	for _, name := range names {
+		dataset, err := runner.executor.Dataset(name, "")
+		if err != nil {
+			jobs = append(jobs, &batchJob{title: name, name: name, err: err})
+		} else if dataset != nil {
+			jobs = append(jobs, runner.dataJobs(name, dataset)...)
+		} else {
+			jobs = append(jobs, &batchJob{title: name, name: name})
+		}
+	}
+	return runner.run(jobs, nil)
+}
+
+// RunData executes a request once per row of the dataset, binding the columns to its variables.
+func (runner *BatchRunner) RunData(name string, dataset *Dataset, args []string) int {
+	return runner.run(runner.dataJobs(name, dataset), args)
+}
+
+func (runner *BatchRunner) dataJobs(name string, dataset *Dataset) []*batchJob {
+	jobs := make([]*batchJob, len(dataset.Rows))
+	for i, row := range dataset.Rows {
+		jobs[i] = &batchJob{title: name + " " + dataset.Label(runner.executor.conf, i), name: name, variables: row}
+	}
+	return jobs
+}
+
+func (runner *BatchRunner) run(jobs []*batchJob, args []string) int {
+	start := time.Now()
+	indexes := make(chan int, len(jobs))
+	for i := range jobs {
+		jobs[i].done = make(chan struct{})
+		indexes <- i
+	}
+	close(indexes)
+
+	var workers sync.WaitGroup
+	for w := 0; w < runner.parallel && w < len(jobs); w++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for i := range indexes {
+				job := jobs[i]
+				if job.err == nil {
+					job.response, job.err = runner.executor.ExecuteRequestWithVariables(job.name, job.variables, args)
+				}
+				close(job.done)
+			}
+		}()
+	}
+
+	var failures []string
+	for _, job := range jobs {
+		<-job.done
+		runner.output.PrintTitle(job.title)
+		runner.output.Print(job.name, job.response, job.err)
+		if job.err != nil {
+			failures = append(failures, fmt.Sprintf("FAIL %v\n    %v", job.title, job.err))
+		} else if job.response.StatusCode >= 400 {
+			failures = append(failures, fmt.Sprintf("FAIL %v\n    status %v", job.title, job.response.StatusCode))
+		}
+	}
+	workers.Wait()
+
+	fmt.Fprintln(runner.writer)
+	for _, failure := range failures {
+		fmt.Fprintln(runner.writer, failure)
+	}
+	fmt.Fprintf(runner.writer, "%v succeeded, %v failed (%v)\n", len(jobs)-len(failures), len(failures), time.Since(start).Round(time.Millisecond))
+	return len(failures)
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const batchConfiguration = `
+url: local
+
+endpoints:
+  test:
+    path: /test/{id}
+
+requests:
+  get_a:
+    endpoint: test
+    id: a
+  get_b:
+    endpoint: test
+    id: b
+  get_c:
+    endpoint: test
+    id: c
+  delete_a:
+    endpoint: test
+    id: a
+`
+
+func TestSelectRequests(t *testing.T) {
+	reader := &MockReader{configurations: make(map[string][]byte)}
+	reader.configurations["test"] = []byte(batchConfiguration)
+	conf, err := NewConfiguration(reader)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	executor := NewExecutor(conf, &MockResponseRunner{}, &MockVariableReader{})
+
+	for _, selection := range []struct {
+		args    []string
+		all     bool
+		several bool
+		names   []string
+		batch   bool
+	}{
+		{[]string{"get_a"}, false, false, []string{"get_a"}, false},
+		{[]string{"test", "1"}, false, false, []string{"test"}, false},
+		{[]string{"delete_a", "get_a"}, false, false, []string{"delete_a"}, false},
+		{[]string{"delete_a", "get_*"}, false, false, []string{"delete_a"}, false},
+		{[]string{"get_*"}, false, false, []string{"get_a", "get_b", "get_c"}, true},
+		{[]string{"get_[bc]", "delete_a", "get_b"}, false, false, []string{"get_b", "get_c", "delete_a"}, true},
+		{[]string{"delete_a", "get_[bc]", "get_b"}, false, true, []string{"delete_a", "get_b", "get_c"}, true},
+		{[]string{"get_a"}, false, true, []string{"get_a"}, true},
+		{nil, true, false, []string{"delete_a", "get_a", "get_b", "get_c"}, true},
+	} {
+		names, batch, err := executor.SelectRequests(selection.args, selection.all, selection.several)
+		if err != nil || batch != selection.batch || !reflect.DeepEqual(names, selection.names) {
+			t.Errorf("Unexpected selection for %v: %v %v %v", selection.args, names, batch, err)
+		}
+	}
+
+	if _, _, err := executor.SelectRequests([]string{"get_*", "post_*"}, false, false); err == nil || err.Error() != "No request matches 'post_*'" {
+		t.Error("Should have thrown a no match error but got ", err)
+	}
+	if _, _, err := executor.SelectRequests([]string{"get_a", "1"}, false, true); err == nil || err.Error() != "No request matches '1'" {
+		t.Error("Should have thrown a no match error but got ", err)
+	}
+	if _, _, err := executor.SelectRequests([]string{"get_a"}, true, false); err == nil {
+		t.Error("Should not accept names with --all")
+	}
+}
+
+func TestBatchRunner(t *testing.T) {
+	reader := &MockReader{configurations: make(map[string][]byte)}
+	reader.configurations["test"] = []byte(batchConfiguration)
+	conf, err := NewConfiguration(reader)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	runner := &ConcurrentMockRunner{delays: map[string]time.Duration{"get_a": 30 * time.Millisecond}, responses: map[string]*Response{
+		"get_a": {StatusCode: 200, Body: []byte("a")},
+		"get_b": {StatusCode: 500, Body: []byte("b")},
+		"get_c": {StatusCode: 200, Body: []byte("c")},
+	}, errors: map[string]error{"delete_a": errors.New("refused")}}
+	executor := NewExecutor(conf, runner, &MockVariableReader{})
+
+	var out, summary bytes.Buffer
+	output, _ := NewResponsePrinter(&out, OUTPUT_RAW, COLOR_NEVER, nil)
+	failed := NewBatchRunner(executor, output, &summary, 4).Run([]string{"get_a", "get_b", "get_c", "delete_a"})
+
+	if failed != 2 {
+		t.Errorf("Should have 2 failed requests but got %v", failed)
+	}
+	if runner.maxRunning < 2 {
+		t.Errorf("Should have run the requests in parallel but got %v at most", runner.maxRunning)
+	}
+	if out.String() != "#### get_a ####\na\n#### get_b ####\nb\n#### get_c ####\nc\n#### delete_a ####\n" {
+		t.Errorf("Should have printed the responses in order but got %v", out.String())
+	}
+	if !strings.HasPrefix(summary.String(), "\nFAIL get_b\n    status 500\nFAIL delete_a\n    refused\n2 succeeded, 2 failed (") {
+		t.Errorf("Unexpected summary %v", summary.String())
+	}
+}
+
+// ConcurrentMockRunner answers with canned responses and counts the requests running at once.
+type ConcurrentMockRunner struct {
+	mutex      sync.Mutex
+	running    int
+	maxRunning int
+	delays     map[string]time.Duration
+	responses  map[string]*Response
+	errors     map[string]error
+}
+
+func (runner *ConcurrentMockRunner) Run(request *Request, command []string) (*Response, error) {
+	runner.mutex.Lock()
+	runner.running++
+	if runner.running > runner.maxRunning {
+		runner.maxRunning = runner.running
+	}
+	runner.mutex.Unlock()
+
+	time.Sleep(runner.delays[request.Name] + 5*time.Millisecond)
+
+	runner.mutex.Lock()
+	defer runner.mutex.Unlock()
+	runner.running--
+	if err, ok := runner.errors[request.Name]; ok {
+		return nil, err
+	}
+	response := *runner.responses[request.Name]
+	return &response, nil
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BenchOptions tell how many requests 'gohit bench' sends. With a duration the requests
+// stop when it's over, even if Requests haven't been sent yet.
+type BenchOptions struct {
+	Requests    int
+	Concurrency int
+	Duration    time.Duration
+	// Rate is the requests per second, 0 for as fast as possible
+	Rate float64
+}
+
+// BenchReport is the outcome of a bench, the latencies are of the calls that got a response.
+type BenchReport struct {
+	Sent      int
+	Elapsed   time.Duration
+	Latencies []time.Duration
+	Statuses  map[int]int
+	Errors    map[string]int
+	// Ignored are the curl options the native client doesn't understand
+	Ignored []string
+}
+
+// Bench sends the resolved request again and again with the native http client.
+func Bench(request *Request, options *BenchOptions) (*BenchReport, error) {
+	settings, unsupported, err := parseHttpSettings(request)
+	if err != nil {
+		return nil, err
+	}
+	if options.Concurrency < 1 {
+		options.Concurrency = 1
+	}
+	if options.Requests <= 0 && options.Duration <= 0 {
+		return nil, errors.New("Either a number of requests or a duration is needed")
+	}
+	client := newHttpClient(settings)
+	client.Transport.(*http.Transport).MaxIdleConnsPerHost = options.Concurrency
+
+	report := &BenchReport{Statuses: make(map[int]int), Errors: make(map[string]int), Ignored: unsupported}
+	var mutex sync.Mutex
+	jobs := make(chan struct{})
+	var workers sync.WaitGroup
+	for w := 0; w < options.Concurrency; w++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for range jobs {
+				response, err := benchCall(client, request, settings)
+				mutex.Lock()
+				if err != nil {
+					report.Errors[err.Error()]++
+				} else {
+					report.Statuses[response.StatusCode]++
+					report.Latencies = append(report.Latencies, response.Elapsed)
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+
+	start := time.Now()
+	var ticker *time.Ticker
+	if options.Rate > 0 {
+		ticker = time.NewTicker(time.Duration(float64(time.Second) / options.Rate))
+		defer ticker.Stop()
+	}
+	var deadline <-chan time.Time
+	if options.Duration > 0 {
+		timer := time.NewTimer(options.Duration)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+send:
+	for options.Requests <= 0 || report.Sent < options.Requests {
+		if ticker != nil {
+			select {
+			case <-ticker.C:
+			case <-deadline:
+				break send
+			}
+		}
+		select {
+		case jobs <- struct{}{}:
+			report.Sent++
+		case <-deadline:
+			break send
+		}
+	}
+	close(jobs)
+	workers.Wait()
+	report.Elapsed = time.Since(start)
+	return report, nil
+}
+
+func benchCall(client *http.Client, request *Request, settings *httpSettings) (*Response, error) {
+	httpRequest, err := newHttpRequest(request, settings)
+	if err != nil {
+		return nil, err
+	}
+	return doHttpRequest(client, httpRequest)
+}
+
+// ParseRate reads a rate like '100', '100/s' or '600/m' as requests per second.
+func ParseRate(rate string) (float64, error) {
+	if rate == "" {
+		return 0, nil
+	}
+	unit := time.Second
+	if i := strings.Index(rate, "/"); i >= 0 {
+		switch rate[i+1:] {
+		case "s":
+		case "m":
+			unit = time.Minute
+		case "h":
+			unit = time.Hour
+		default:
+			return 0, errors.New(fmt.Sprintf("Invalid rate '%v', expected e.g. 100/s", rate))
+		}
+		rate = rate[:i]
+	}
+	value, err := strconv.ParseFloat(rate, 64)
+	if err != nil || value <= 0 {
+		return 0, errors.New(fmt.Sprintf("Invalid rate '%v', expected e.g. 100/s", rate))
+	}
+	return value / unit.Seconds(), nil
+}
+
+// Percentile returns the latency under which p percent of the calls are, by nearest rank.
+func (report *BenchReport) Percentile(p float64) time.Duration {
+	if len(report.Latencies) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), report.Latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	rank := int(p/100*float64(len(sorted))+0.999999) - 1
+	if rank < 0 {
+		rank = 0
+	} else if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
+
+func (report *BenchReport) Print(writer io.Writer) {
+	if len(report.Ignored) > 0 {
+		fmt.Fprintf(writer, "Options not supported by the http client, ignored: %v\n\n", strings.Join(report.Ignored, " "))
+	}
+	throughput := 0.0
+	if report.Elapsed > 0 {
+		throughput = float64(report.Sent) / report.Elapsed.Seconds()
+	}
+	fmt.Fprintf(writer, "Requests:    %v in %v, %.1f req/s\n", report.Sent, report.Elapsed.Round(time.Millisecond), throughput)
+	if len(report.Latencies) > 0 {
+		fmt.Fprintf(writer, "Latency:     min %v, p50 %v, p90 %v, p95 %v, p99 %v, max %v\n",
+			benchDuration(report.Percentile(0)), benchDuration(report.Percentile(50)), benchDuration(report.Percentile(90)),
+			benchDuration(report.Percentile(95)), benchDuration(report.Percentile(99)), benchDuration(report.Percentile(100)))
+	}
+
+	statuses := make([]int, 0, len(report.Statuses))
+	for status := range report.Statuses {
+		statuses = append(statuses, status)
+	}
+	sort.Ints(statuses)
+	for i, status := range statuses {
+		label := ""
+		if i == 0 {
+			label = "Status:"
+		}
+		fmt.Fprintf(writer, "%-12v %v: %v\n", label, status, report.Statuses[status])
+	}
+
+	errorCount := 0
+	messages := make([]string, 0, len(report.Errors))
+	for message, count := range report.Errors {
+		errorCount += count
+		messages = append(messages, message)
+	}
+	sort.Strings(messages)
+	fmt.Fprintf(writer, "Errors:      %v\n", errorCount)
+	for _, message := range messages {
+		fmt.Fprintf(writer, "    %v: %v\n", report.Errors[message], message)
+	}
+}
+
+// benchDuration keeps a tenth of a millisecond, enough for the latencies of a local api.
+func benchDuration(duration time.Duration) time.Duration {
+	return duration.Round(100 * time.Microsecond)
+}
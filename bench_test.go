@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBench(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Custom") != "value" || r.URL.Query().Get("id") != "1" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if atomic.AddInt32(&calls, 1)%5 == 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	request := &Request{Method: "GET", Url: server.URL, Path: "/test", QueryRaw: "id=1", Headers: []string{"Custom: value"}, Options: []string{"--silent", "--verbose"}}
+	report, err := Bench(request, &BenchOptions{Requests: 50, Concurrency: 5})
+	if err != nil {
+		t.Error("Should not throw an error ", err)
+		return
+	}
+	if report.Sent != 50 || len(report.Latencies) != 50 || report.Statuses[200] != 40 || report.Statuses[503] != 10 {
+		t.Errorf("Unexpected report %v %v %v", report.Sent, len(report.Latencies), report.Statuses)
+	}
+	if len(report.Ignored) != 1 || report.Ignored[0] != "--verbose" {
+		t.Errorf("Should have ignored --verbose but got %v", report.Ignored)
+	}
+}
+
+func TestBenchDurationAndRate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	request := &Request{Method: "GET", Url: server.URL, Path: "/test"}
+	report, err := Bench(request, &BenchOptions{Concurrency: 2, Duration: 300 * time.Millisecond, Rate: 20})
+	if err != nil {
+		t.Error("Should not throw an error ", err)
+		return
+	}
+	if report.Sent < 3 || report.Sent > 7 || report.Elapsed < 300*time.Millisecond {
+		t.Errorf("Should have sent about 6 requests in 300ms but sent %v in %v", report.Sent, report.Elapsed)
+	}
+}
+
+func TestBenchErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	address := server.URL
+	server.Close()
+
+	report, err := Bench(&Request{Method: "GET", Url: address, Path: "/test"}, &BenchOptions{Requests: 4, Concurrency: 2})
+	if err != nil {
+		t.Error("Should not throw an error ", err)
+		return
+	}
+	var b bytes.Buffer
+	report.Print(&b)
+	if len(report.Latencies) != 0 || len(report.Statuses) != 0 || !strings.Contains(b.String(), "Errors:      4\n    4: Get ") {
+		t.Errorf("Should have reported the errors but got %v", b.String())
+	}
+}
+
+func TestBenchReport(t *testing.T) {
+	report := &BenchReport{
+		Sent:     10,
+		Elapsed:  2 * time.Second,
+		Statuses: map[int]int{200: 9, 500: 1},
+		Errors:   map[string]int{},
+	}
+	for i := 10; i >= 1; i-- {
+		report.Latencies = append(report.Latencies, time.Duration(i)*time.Millisecond)
+	}
+	if report.Percentile(50) != 5*time.Millisecond || report.Percentile(90) != 9*time.Millisecond || report.Percentile(99) != 10*time.Millisecond {
+		t.Errorf("Unexpected percentiles %v %v %v", report.Percentile(50), report.Percentile(90), report.Percentile(99))
+	}
+
+	var b bytes.Buffer
+	report.Print(&b)
+	expected := `Requests:    10 in 2s, 5.0 req/s
+Latency:     min 1ms, p50 5ms, p90 9ms, p95 10ms, p99 10ms, max 10ms
+Status:      200: 9
+             500: 1
+Errors:      0
+`
+	if b.String() != expected {
+		t.Errorf("Unexpected report %v", b.String())
+	}
+}
+
+func TestParseRate(t *testing.T) {
+	for rate, expected := range map[string]float64{"": 0, "100": 100, "50/s": 50, "120/m": 2, "3600/h": 1} {
+		if value, err := ParseRate(rate); err != nil || value != expected {
+			t.Errorf("Unexpected rate for %v: %v %v", rate, value, err)
+		}
+	}
+	for _, rate := range []string{"fast", "10/d", "0/s"} {
+		if _, err := ParseRate(rate); err == nil {
+			t.Errorf("Should not accept rate %v", rate)
+		}
+	}
+}
+
+func TestResolveRequest(t *testing.T) {
+	reader := &MockReader{configurations: make(map[string][]byte)}
+	reader.configurations["test"] = []byte(batchConfiguration)
+	conf, err := NewConfiguration(reader)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	executor := NewExecutor(conf, &MockCommandRunner{}, &MockVariableReader{})
+
+	if request, err := executor.ResolveRequest("get_b", nil); err != nil || request.Url+request.Path != "local/test/b" {
+		t.Errorf("Unexpected request %v %v", request, err)
+	}
+	if request, err := executor.ResolveRequest("test", []string{"9"}); err != nil || request.Url+request.Path != "local/test/9" {
+		t.Errorf("Unexpected endpoint request %v %v", request, err)
+	}
+	if _, err := executor.ResolveRequest("missing", nil); err == nil {
+		t.Error("Should have thrown a not found error")
+	}
+}
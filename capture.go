@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+)
+
+// Capture extracts a value from a response, by json path, header name or regular
+// expression over the body, to be used as a variable by the requests run after it.
+type Capture struct {
+	Json   string
+	Header string
+	Regex  string
+}
+
+const (
+	CAPTURE_JSON   = "json"
+	CAPTURE_HEADER = "header"
+	CAPTURE_REGEX  = "regex"
+)
+
+// readCaptures reads the capture block of a request. A plain string is a json path.
+func readCaptures(value interface{}) (map[string]*Capture, error) {
+	definition, ok := value.(map[interface{}]interface{})
+	if !ok {
+		return nil, errors.New("capture must be a map")
+	}
+	captures := make(map[string]*Capture, len(definition))
+	for name, value := range definition {
+		capture := &Capture{}
+		switch source := value.(type) {
+		case string:
+			capture.Json = source
+		case map[interface{}]interface{}:
+			if len(source) != 1 {
+				return nil, errors.New(fmt.Sprintf("Capture '%v' must have one of json, header or regex", name))
+			}
+			for kind, expression := range source {
+				switch kind {
+				case CAPTURE_JSON:
+					capture.Json = fmt.Sprint(expression)
+				case CAPTURE_HEADER:
+					capture.Header = fmt.Sprint(expression)
+				case CAPTURE_REGEX:
+					if _, err := regexp.Compile(fmt.Sprint(expression)); err != nil {
+						return nil, err
+					}
+					capture.Regex = fmt.Sprint(expression)
+				default:
+					return nil, errors.New(fmt.Sprintf("Invalid capture attribute '%v'", kind))
+				}
+			}
+		default:
+			return nil, errors.New(fmt.Sprintf("Invalid capture '%v'", name))
+		}
+		captures[fmt.Sprint(name)] = capture
+	}
+	return captures, nil
+}
+
+func (capture *Capture) Extract(response *Response) (string, error) {
+	if capture.Header != "" {
+		values, ok := response.Headers[http.CanonicalHeaderKey(capture.Header)]
+		if !ok {
+			return "", errors.New(fmt.Sprintf("header %v missing", capture.Header))
+		}
+		return values[0], nil
+	}
+
+	if capture.Regex != "" {
+		match := regexp.MustCompile(capture.Regex).FindSubmatch(response.Body)
+		if match == nil {
+			return "", errors.New(fmt.Sprintf("regex '%v' doesn't match the body", capture.Regex))
+		}
+		if len(match) > 1 {
+			return string(match[1]), nil
+		}
+		return string(match[0]), nil
+	}
+
+	var document interface{}
+	if err := json.Unmarshal(response.Body, &document); err != nil {
+		return "", errors.New("body is not json: " + err.Error())
+	}
+	value, found := jsonPath(document, capture.Json)
+	if !found {
+		return "", errors.New(fmt.Sprintf("json %v missing", capture.Json))
+	}
+	return jsonValueAsString(value), nil
+}
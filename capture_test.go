@@ -0,0 +1,202 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCaptureIntoSession(t *testing.T) {
+	reader := &MockReader{configurations: make(map[string][]byte)}
+	reader.configurations["test"] = []byte(
+		`
+url: local
+
+endpoints:
+  login:
+    method: POST
+    path: /login
+  me:
+    path: /me/{user_id}
+    headers:
+      - 'Authorization: Bearer {token}'
+
+requests:
+  login_admin:
+    endpoint: login
+    capture:
+      token: access_token
+      user_id:
+        json: user.id
+      session:
+        header: Set-Cookie
+      csrf:
+        regex: '"form": "csrf-(\w+)"'
+  show_me:
+    endpoint: me
+`)
+
+	conf, err := NewConfiguration(reader)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	runner := &MockResponseRunner{responses: map[string]*Response{
+		"login_admin": {
+			StatusCode: 200,
+			Headers:    http.Header{"Set-Cookie": []string{"id=1"}},
+			Body:       []byte(`{"access_token": "abc", "user": {"id": 7}, "form": "csrf-xyz"}`),
+		},
+	}}
+	executor := NewExecutor(conf, runner, &MockVariableReader{})
+
+	if _, err := executor.ExecuteRequest("login_admin", nil); err != nil {
+		t.Error("Should not throw an error ", err)
+		return
+	}
+	for name, expected := range map[string]string{"token": "abc", "user_id": "7", "session": "id=1", "csrf": "xyz"} {
+		if value, _ := conf.Session.Get(name); value != expected {
+			t.Errorf("Session %v should be %v but got %v", name, expected, value)
+		}
+	}
+
+	if _, err := executor.ExecuteRequest("show_me", nil); err != nil {
+		t.Error("Should not throw an error ", err)
+		return
+	}
+	request := runner.executed[1]
+	if request.Path != "/me/7" || !containsString(request.Headers, "Authorization: Bearer abc") {
+		t.Errorf("Should have used the captured variables %v", request)
+	}
+}
+
+func TestCaptureMissing(t *testing.T) {
+	reader := &MockReader{configurations: make(map[string][]byte)}
+	reader.configurations["test"] = []byte(
+		`
+url: local
+
+endpoints:
+  login:
+    path: /login
+
+requests:
+  login_admin:
+    endpoint: login
+    capture:
+      token: access_token
+`)
+
+	conf, err := NewConfiguration(reader)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	executor := NewExecutor(conf, &MockResponseRunner{}, &MockVariableReader{})
+
+	if _, err := executor.ExecuteRequest("login_admin", nil); err == nil ||
+		err.Error() != "Request login_admin couldn't capture token: body is not json: unexpected end of JSON input" {
+		t.Error("Should have thrown a capture error but got ", err)
+	}
+}
+
+func TestInvalidCapture(t *testing.T) {
+	reader := &MockReader{configurations: make(map[string][]byte)}
+	reader.configurations["test"] = []byte(
+		`
+url: local
+
+endpoints:
+  login:
+    path: /login
+
+requests:
+  login_admin:
+    endpoint: login
+    capture:
+      token:
+        xpath: /token
+`)
+
+	if _, err := NewConfiguration(reader); err == nil ||
+		err.Error() != "Request login_admin has an invalid capture: Invalid capture attribute 'xpath'" {
+		t.Error("Should have thrown an invalid capture error but got ", err)
+	}
+}
+
+func TestFileVariableStore(t *testing.T) {
+	directory, _ := ioutil.TempDir("", "gohit")
+	defer os.RemoveAll(directory)
+	file := filepath.Join(directory, SESSION_FILE)
+
+	store, err := NewFileVariableStore(file)
+	if err != nil {
+		t.Error("Should not throw an error ", err)
+		return
+	}
+	store.Set("token", "abc")
+	if err := store.Save(); err != nil {
+		t.Error("Should not throw an error ", err)
+	}
+
+	store, _ = NewFileVariableStore(file)
+	if value, ok := store.Get("token"); !ok || value != "abc" {
+		t.Errorf("Should have loaded the saved token but got %v", value)
+	}
+
+	if err := store.Clear(); err != nil {
+		t.Error("Should not throw an error ", err)
+	}
+	if _, err := os.Stat(file); !os.IsNotExist(err) || len(store.Names()) != 0 {
+		t.Errorf("Should have removed the session file but got %v %v", err, store.Names())
+	}
+}
+
+func TestArgsOverSession(t *testing.T) {
+	reader := &MockReader{configurations: make(map[string][]byte)}
+	reader.configurations["test"] = []byte(
+		`
+url: local
+
+endpoints:
+  get_item:
+    path: /users/{id}/items/{item}
+    headers:
+      - 'Authorization: Bearer {token}'
+`)
+
+	conf, err := NewConfiguration(reader)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	conf.Session.Set("id", "7")
+	conf.Session.Set("token", "abc")
+	runner := &MockResponseRunner{}
+	executor := NewExecutor(conf, runner, &MockVariableReader{})
+
+	for _, run := range []struct {
+		args    []string
+		path    string
+		headers string
+	}{
+		{nil, "/users/7/items/value", "Authorization: Bearer abc"},
+		{[]string{"42", "3"}, "/users/42/items/3", "Authorization: Bearer abc"},
+		{[]string{"42", "3", "xyz"}, "/users/42/items/3", "Authorization: Bearer xyz"},
+		{[]string{"42"}, "/users/42/items/value", "Authorization: Bearer abc"},
+	} {
+		if _, err := executor.ExecuteRequest("get_item", run.args); err != nil {
+			t.Error("Should not throw an error ", err)
+			return
+		}
+		request := runner.executed[len(runner.executed)-1]
+		if request.Path != run.path || !containsString(request.Headers, run.headers) {
+			t.Errorf("Unexpected request for %v: %v %v", run.args, request.Path, request.Headers)
+		}
+	}
+	if value, _ := conf.Session.Get("id"); value != "7" {
+		t.Errorf("Should have kept the session id but got %v", value)
+	}
+}
@@ -1,21 +1,32 @@
 package main
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/smallfish/simpleyaml"
 	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 )
 
 type Configuration struct {
+	Runner          string
+	HistoryLimit    int
 	GlobalUrl       string
-	GlobalHeaders   map[string]bool
-	GlobalOptions   map[string]bool
+	GlobalHeaders   []string
+	GlobalOptions   []string
 	GlobalVariables map[string]interface{}
+	Variables       map[string]interface{}
 	Endpoints       map[string]*Endpoint
 	Requests        map[string]*Request
+	Session         *VariableStore
+	Flows           map[string]*Flow
+	Environment     string
+	Environments    map[string]*Environment
 
 	requestsConfiguration map[string]map[interface{}]interface{}
 	reader                ConfReader
@@ -25,8 +36,13 @@ type ConfReader interface {
 	Read() error
 	Directory() string
 	Configuration() map[string][]byte
+	DotEnv() map[string]string
 }
 
+// environmentVariablePattern matches {env:NAME} and ${NAME}, resolved from the process
+// environment or the .env file.
+var environmentVariablePattern = regexp.MustCompile(`{env:([\w.\-]+)}|\$\{([\w.\-]+)}`)
+
 const (
 	URL        = "url"
 	OPTIONS    = "options"
@@ -34,23 +50,53 @@ const (
 	FILES      = "files"
 	VARIABLES  = "variables"
 	PARAMETERS = "parameters"
+	RUNNER     = "runner"
+	HISTORY    = "history"
 
 	ENDPOINTS = "endpoints"
 	PATH      = "path"
 	METHOD    = "method"
 	QUERY     = "query"
+	BODY      = "body"
 
 	REQUESTS = "requests"
 	ENDPOINT = "endpoint"
+	EXPECT   = "expect"
+	CAPTURE  = "capture"
+	IGNORE   = "ignore"
+	DATA     = "data"
+	MATRIX   = "matrix"
 )
 
+// requestAttributes are the keys of a request that aren't variables.
+var requestAttributes = map[string]bool{
+	ENDPOINT: true,
+	BODY:     true,
+	HEADERS:  true,
+	OPTIONS:  true,
+	EXPECT:   true,
+	CAPTURE:  true,
+	IGNORE:   true,
+	DATA:     true,
+	MATRIX:   true,
+}
+
 func NewConfiguration(confReader ConfReader) (*Configuration, error) {
+	return NewEnvironmentConfiguration(confReader, "")
+}
+
+// NewEnvironmentConfiguration loads the configuration with the named environment overlaid.
+func NewEnvironmentConfiguration(confReader ConfReader, environment string) (*Configuration, error) {
 	configuration := &Configuration{
-		GlobalHeaders:         make(map[string]bool),
-		GlobalOptions:         make(map[string]bool),
+		HistoryLimit:          DEFAULT_HISTORY_LIMIT,
 		GlobalVariables:       make(map[string]interface{}),
+		Variables:             make(map[string]interface{}),
 		Endpoints:             make(map[string]*Endpoint),
 		Requests:              make(map[string]*Request),
+		Session:               NewVariableStore(),
+		Flows:                 make(map[string]*Flow),
+		Environment:           environment,
+		Environments:          make(map[string]*Environment),
 		requestsConfiguration: make(map[string]map[interface{}]interface{}),
 		reader:                confReader,
 	}
@@ -70,6 +116,9 @@ func (conf *Configuration) init() error {
 			return err
 		}
 	}
+	if err := conf.loadEnvironment(); err != nil {
+		return err
+	}
 	conf.loadEndpointGlobals()
 	if err := conf.loadRequests(); err != nil {
 		return err
@@ -83,6 +132,9 @@ func (conf *Configuration) init() error {
 }
 
 func (conf *Configuration) validate() error {
+	if err := conf.SetRunner(conf.Runner); err != nil {
+		return err
+	}
 	if len(conf.Endpoints) == 0 {
 		return errors.New("Missing endpoints")
 	}
@@ -100,18 +152,34 @@ func (conf *Configuration) validate() error {
 		}
 	}
 
+	for _, flow := range conf.Flows {
+		for i, step := range flow.Steps {
+			if conf.Requests[step.Request] == nil && conf.Endpoints[step.Request] == nil {
+				return errors.New(fmt.Sprintf("Flow '%v' step %v couldn't find request %v", flow.Name, i+1, step.Request))
+			}
+		}
+	}
+
+	return nil
+}
+
+// SetRunner chooses how requests are executed, either by curl or by the native http runner.
+func (conf *Configuration) SetRunner(runner string) error {
+	if _, ok := runners[runner]; !ok {
+		return errors.New(fmt.Sprintf("Invalid runner '%v'", runner))
+	}
+	conf.Runner = runner
 	return nil
 }
 
 func (conf *Configuration) loadEndpointGlobals() {
 	for _, endpoint := range conf.Endpoints {
-		for globalHeader := range conf.GlobalHeaders {
-			endpoint.Headers[globalHeader] = true
+		if endpoint.Url == "" {
+			endpoint.Url = conf.GlobalUrl
 		}
 
-		for globalOption := range conf.GlobalOptions {
-			endpoint.Options[globalOption] = true
-		}
+		endpoint.Headers = mergeHeaders(conf.GlobalHeaders, endpoint.Headers)
+		endpoint.Options = mergeOptions(conf.GlobalOptions, endpoint.Options)
 	}
 }
 
@@ -134,20 +202,14 @@ func (conf *Configuration) readConfiguration(moduleDefinition string, source []b
 		return err
 	}
 
-	var files []interface{}
 	for key := range asMap {
-		if conf.isConfiguration(key.(string)) {
-			if imports, err := conf.addConfiguration(key.(string), yaml); err != nil {
-				return err
-			} else if imports != nil {
-				files = imports
-			}
-		} else if key != ENDPOINTS && key != REQUESTS {
+		if !conf.isConfiguration(key.(string)) && key != ENDPOINTS && key != REQUESTS && key != FLOWS && key != ENVIRONMENTS {
 			return errors.New(fmt.Sprintf("Invalid yaml attribute '%v'", key))
 		}
 	}
 
-	if files != nil {
+	// imported files are read first, so this file globals are declared after theirs and override them
+	if files, err := yaml.Get(FILES).Array(); err == nil {
 		for i := range files {
 			fileName := files[i].(string)
 			source, err := ioutil.ReadFile(conf.reader.Directory() + "/" + fileName)
@@ -160,6 +222,12 @@ func (conf *Configuration) readConfiguration(moduleDefinition string, source []b
 		}
 	}
 
+	for key := range asMap {
+		if conf.isConfiguration(key.(string)) {
+			conf.addConfiguration(key.(string), yaml)
+		}
+	}
+
 	endpointsMap := asMap[ENDPOINTS]
 	if endpointsMap != nil {
 		if err := conf.readEndpoints(endpointsMap.(map[interface{}]interface{}), yaml); err != nil {
@@ -171,17 +239,36 @@ func (conf *Configuration) readConfiguration(moduleDefinition string, source []b
 		conf.requestsConfiguration[moduleDefinition] = requestsMap.(map[interface{}]interface{})
 	}
 
+	if environmentsMap, ok := asMap[ENVIRONMENTS].(map[interface{}]interface{}); ok {
+		for name, environment := range environmentsMap {
+			if conf.Environments[name.(string)], err = readEnvironment(name.(string), environment); err != nil {
+				return err
+			}
+		}
+	}
+
+	if flowsMap, ok := asMap[FLOWS].(map[interface{}]interface{}); ok {
+		for name, steps := range flowsMap {
+			if conf.Flows[name.(string)], err = readFlow(name.(string), steps); err != nil {
+				return err
+			}
+		}
+	}
+
 	return nil
 }
 
 func (conf *Configuration) readRequests(requestsMap map[interface{}]interface{}) error {
-	var err error
 	for key := range requestsMap {
-		keyAsString := key.(string)
-		conf.Requests[keyAsString], err = conf.createRequest(keyAsString, requestsMap[key])
+		requests, err := conf.expandMatrix(key.(string), requestsMap[key])
 		if err != nil {
 			return err
 		}
+		for name, value := range requests {
+			if conf.Requests[name], err = conf.createRequest(name, value); err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
@@ -197,10 +284,57 @@ func (conf *Configuration) readEndpoints(endpointMap map[interface{}]interface{}
 }
 
 func (conf *Configuration) createRequest(name string, value interface{}) (*Request, error) {
+	request, err := conf.newRequest(name, value)
+	if err != nil {
+		return nil, err
+	}
+	conf.replaceEnvironmentVariables(request)
+	return request, nil
+}
+
+// newRequest creates a request leaving the environment placeholders in place.
+func (conf *Configuration) newRequest(name string, value interface{}) (*Request, error) {
+	return conf.newSessionRequest(name, value, conf.Session)
+}
+
+// newSessionRequest creates a request with the variables of the given session.
+func (conf *Configuration) newSessionRequest(name string, value interface{}, session *VariableStore) (*Request, error) {
+	request, endpoint, err := conf.newRequestDefinition(name, value)
+	if err != nil {
+		return nil, err
+	}
+
+	for k := range conf.Variables {
+		toReplace := "{" + k + "}"
+		conf.replaceAll(request, toReplace, conf.Variables[k])
+	}
+
+	for k := range request.Parameters {
+		toReplace := "{" + k.(string) + "}"
+		conf.replaceAll(request, toReplace, request.Parameters[k])
+	}
+
+	for _, k := range session.Names() {
+		value, _ := session.Get(k)
+		conf.replaceAll(request, "{"+k+"}", value)
+	}
+
+	for k := range endpoint.Parameters {
+		toReplace := "{" + k + "}"
+		conf.replaceAll(request, toReplace, endpoint.Parameters[k])
+	}
+
+	for k := range conf.GlobalVariables {
+		toReplace := "{" + k + "}"
+		conf.replaceAll(request, toReplace, conf.GlobalVariables[k])
+	}
+	return request, nil
+}
+
+// newRequestDefinition creates a request from its endpoint and attributes, without replacing any variable.
+func (conf *Configuration) newRequestDefinition(name string, value interface{}) (*Request, *Endpoint, error) {
 	request := &Request{
 		Name:      name,
-		Headers:   make(map[string]bool),
-		Options:   make(map[string]bool),
 		QueryList: make(map[string]string),
 	}
 
@@ -209,45 +343,142 @@ func (conf *Configuration) createRequest(name string, value interface{}) (*Reque
 	endpointName := request.Parameters[ENDPOINT].(string)
 	endpoint := conf.Endpoints[endpointName]
 	if endpoint == nil {
-		return nil, errors.New(fmt.Sprintf("Request %v couldn't find endpoint %v", name, endpointName))
+		return nil, nil, errors.New(fmt.Sprintf("Request %v couldn't find endpoint %v", name, endpointName))
 	}
+	var err error
 	request.Method = endpoint.Method
 	request.Url = endpoint.Url
 	request.Path = endpoint.Path
 	request.QueryRaw = endpoint.QueryRaw
-	request.QueryList = endpoint.QueryList
+	for k, v := range endpoint.QueryList {
+		request.QueryList[k] = v
+	}
 	request.QueryListKeys = endpoint.QueryListKeys
+	request.Body = endpoint.Body
 
-	for k, v := range endpoint.Headers {
-		request.Headers[k] = v
+	if body, ok := request.Parameters[BODY]; ok {
+		if request.Body, err = conf.readBody(body); err != nil {
+			return nil, nil, errors.New(fmt.Sprintf("Request %v has an invalid body: %v", name, err))
+		}
 	}
 
-	for k, v := range endpoint.Options {
-		request.Options[k] = v
+	if expect, ok := request.Parameters[EXPECT]; ok {
+		if request.Expect, err = readExpectation(expect); err != nil {
+			return nil, nil, errors.New(fmt.Sprintf("Request %v has an invalid expect: %v", name, err))
+		}
 	}
 
-	for k := range request.Parameters {
-		toReplace := "{" + k.(string) + "}"
-		conf.replaceAll(request, toReplace, request.Parameters[k])
+	if capture, ok := request.Parameters[CAPTURE]; ok {
+		if request.Capture, err = readCaptures(capture); err != nil {
+			return nil, nil, errors.New(fmt.Sprintf("Request %v has an invalid capture: %v", name, err))
+		}
 	}
 
-	for k := range endpoint.Parameters {
-		toReplace := "{" + k + "}"
-		conf.replaceAll(request, toReplace, endpoint.Parameters[k])
+	if ignore, ok := request.Parameters[IGNORE].([]interface{}); ok {
+		request.Ignore = asStrings(ignore)
 	}
 
-	for k := range conf.GlobalVariables {
-		toReplace := "{" + k + "}"
-		conf.replaceAll(request, toReplace, conf.GlobalVariables[k])
+	if data, ok := request.Parameters[DATA].(string); ok {
+		request.Data = data
+	}
+
+	request.Headers = mergeHeaders(endpoint.Headers, nil)
+	if headers, ok := request.Parameters[HEADERS].([]interface{}); ok {
+		request.Headers = mergeHeaders(request.Headers, asStrings(headers))
+	}
+
+	request.Options = mergeOptions(endpoint.Options, nil)
+	if options, ok := request.Parameters[OPTIONS].([]interface{}); ok {
+		request.Options = mergeOptions(request.Options, asStrings(options))
+	}
+
+	// a variable named like an attribute would silently become that attribute
+	requestAsString := renderRunCommand(request)
+	for key := range request.Parameters {
+		if key != ENDPOINT && requestAttributes[fmt.Sprint(key)] && strings.Contains(requestAsString, "{"+fmt.Sprint(key)+"}") {
+			return nil, nil, errors.New(fmt.Sprintf("Request %v uses {%v} but '%v' is a request attribute, not a variable: rename the variable", name, key, key))
+		}
+	}
+	return request, endpoint, nil
+}
+
+// replaceEnvironmentVariables replaces the environment placeholders it can resolve and
+// returns the names of the ones it can't.
+func (conf *Configuration) replaceEnvironmentVariables(request *Request) []string {
+	var unresolved []string
+	seen := make(map[string]bool)
+	for _, match := range environmentVariablePattern.FindAllStringSubmatch(renderRunCommand(request), -1) {
+		if seen[match[0]] {
+			continue
+		}
+		seen[match[0]] = true
+		name := match[1] + match[2]
+		if value, ok := conf.lookupEnvironmentVariable(name); ok {
+			conf.replaceAll(request, match[0], value)
+		} else {
+			unresolved = append(unresolved, name)
+		}
+	}
+	sort.Strings(unresolved)
+	return unresolved
+}
+
+// environmentValues returns the placeholders of the environment variables a request uses,
+// by the value they resolve to.
+func (conf *Configuration) environmentValues(request *Request) map[string]string {
+	values := make(map[string]string)
+	for _, match := range environmentVariablePattern.FindAllStringSubmatch(renderRunCommand(request), -1) {
+		if value, ok := conf.lookupEnvironmentVariable(match[1] + match[2]); ok && value != "" {
+			values[value] = match[0]
+		}
+	}
+	return values
+}
+
+func (conf *Configuration) lookupEnvironmentVariable(name string) (string, bool) {
+	if value, ok := os.LookupEnv(name); ok {
+		return value, true
+	}
+	value, ok := conf.reader.DotEnv()[name]
+	return value, ok
+}
+
+func (request *Request) copy() *Request {
+	copied := *request
+	copied.Headers = append([]string(nil), request.Headers...)
+	copied.Options = append([]string(nil), request.Options...)
+	copied.QueryList = make(map[string]string, len(request.QueryList))
+	for k, v := range request.QueryList {
+		copied.QueryList[k] = v
+	}
+	return &copied
+}
+
+// asRequest returns a request with the same definition as the endpoint.
+func (endpoint *Endpoint) asRequest() *Request {
+	return &Request{
+		Name:          endpoint.Name,
+		Url:           endpoint.Url,
+		Path:          endpoint.Path,
+		QueryRaw:      endpoint.QueryRaw,
+		QueryList:     endpoint.QueryList,
+		QueryListKeys: endpoint.QueryListKeys,
+		Method:        endpoint.Method,
+		Headers:       endpoint.Headers,
+		Options:       endpoint.Options,
+		Body:          endpoint.Body,
 	}
-	return request, nil
 }
 
 func (conf *Configuration) replaceAll(request *Request, toReplace string, value interface{}) {
-	replacement := conf.getReplacement(value)
+	replaceInRequest(request, toReplace, conf.getReplacement(value))
+}
+
+func replaceInRequest(request *Request, toReplace string, replacement string) {
 	request.Url = strings.Replace(request.Url, toReplace, replacement, -1)
 	request.Path = strings.Replace(request.Path, toReplace, replacement, -1)
 	request.QueryRaw = strings.Replace(request.QueryRaw, toReplace, replacement, -1)
+	request.Body = strings.Replace(request.Body, toReplace, replacement, -1)
 
 	for name, value := range request.QueryList {
 		replaced := strings.Replace(value, toReplace, replacement, -1)
@@ -256,19 +487,11 @@ func (conf *Configuration) replaceAll(request *Request, toReplace string, value
 		}
 	}
 
-	for header := range request.Headers {
-		replaced := strings.Replace(header, toReplace, replacement, -1)
-		if header != replaced {
-			request.Headers[replaced] = true
-			delete(request.Headers, header)
-		}
+	for i := range request.Headers {
+		request.Headers[i] = strings.Replace(request.Headers[i], toReplace, replacement, -1)
 	}
-	for option := range request.Options {
-		replaced := strings.Replace(option, toReplace, replacement, -1)
-		if option != replaced {
-			request.Options[replaced] = true
-			delete(request.Options, option)
-		}
+	for i := range request.Options {
+		request.Options[i] = strings.Replace(request.Options[i], toReplace, replacement, -1)
 	}
 }
 
@@ -280,6 +503,8 @@ func (conf *Configuration) getReplacement(value interface{}) string {
 		return strconv.FormatBool(value.(bool))
 	case int:
 		return strconv.Itoa(value.(int))
+	case float64:
+		return strconv.FormatFloat(value.(float64), 'f', -1, 64)
 	case string:
 		return value.(string)
 	}
@@ -287,10 +512,8 @@ func (conf *Configuration) getReplacement(value interface{}) string {
 
 func (conf *Configuration) addEndpoint(name string, yaml *simpleyaml.Yaml) error {
 	endpoint := &Endpoint{
-		Name:      name,
-		Headers:   make(map[string]bool),
-		Options:   make(map[string]bool),
-		QueryList: make(map[string]string),
+		Name:       name,
+		QueryList:  make(map[string]string),
 		Parameters: make(map[string]interface{}),
 	}
 	conf.Endpoints[name] = endpoint
@@ -321,8 +544,6 @@ func (conf *Configuration) addEndpoint(name string, yaml *simpleyaml.Yaml) error
 
 	if url, err := yaml.GetPath(ENDPOINTS, name, URL).String(); err == nil {
 		endpoint.Url = url
-	} else {
-		endpoint.Url = conf.GlobalUrl
 	}
 
 	if method, err := yaml.GetPath(ENDPOINTS, name, METHOD).String(); err == nil {
@@ -332,15 +553,11 @@ func (conf *Configuration) addEndpoint(name string, yaml *simpleyaml.Yaml) error
 	}
 
 	if headers, err := yaml.GetPath(ENDPOINTS, name, HEADERS).Array(); err == nil {
-		for i := range headers {
-			endpoint.Headers[headers[i].(string)] = true
-		}
+		endpoint.Headers = asStrings(headers)
 	}
 
 	if options, err := yaml.GetPath(ENDPOINTS, name, OPTIONS).Array(); err == nil {
-		for i := range options {
-			endpoint.Options[options[i].(string)] = true
-		}
+		endpoint.Options = mergeOptions(nil, asStrings(options))
 	}
 
 	if params, err := yaml.GetPath(ENDPOINTS, name, PARAMETERS).Map(); err == nil {
@@ -348,37 +565,139 @@ func (conf *Configuration) addEndpoint(name string, yaml *simpleyaml.Yaml) error
 			endpoint.Parameters[i.(string)] = params[i]
 		}
 	}
+
+	if definition, err := yaml.GetPath(ENDPOINTS, name).Map(); err == nil && definition[BODY] != nil {
+		if endpoint.Body, err = conf.readBody(definition[BODY]); err != nil {
+			return errors.New(fmt.Sprintf("Endpoint '%v' has an invalid body: %v", name, err))
+		}
+	}
+
+	if definition, err := yaml.GetPath(ENDPOINTS, name).Map(); err == nil && definition[MOCK] != nil {
+		if endpoint.Mock, err = conf.readMockResponse(definition[MOCK]); err != nil {
+			return errors.New(fmt.Sprintf("Endpoint '%v' has an invalid mock: %v", name, err))
+		}
+	}
 	return nil
 }
 
-func (conf *Configuration) addConfiguration(name string, yaml *simpleyaml.Yaml) ([]interface{}, error) {
+// readBody accepts an inline string, a '@file' reference relative to the
+// configuration directory or a yaml structure that is sent as json.
+func (conf *Configuration) readBody(value interface{}) (string, error) {
+	switch body := value.(type) {
+	case string:
+		if strings.HasPrefix(body, "@") {
+			source, err := ioutil.ReadFile(conf.reader.Directory() + "/" + strings.TrimPrefix(body, "@"))
+			if err != nil {
+				return "", err
+			}
+			return string(source), nil
+		}
+		return body, nil
+	case map[interface{}]interface{}, []interface{}:
+		asJson, err := json.Marshal(toJsonValue(body))
+		if err != nil {
+			return "", err
+		}
+		return string(asJson), nil
+	default:
+		return conf.getReplacement(body), nil
+	}
+}
+
+// toJsonValue converts the maps created by the yaml parser, which have interface{} keys,
+// into something encoding/json can marshal.
+func toJsonValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		asMap := make(map[string]interface{}, len(v))
+		for key, element := range v {
+			asMap[fmt.Sprint(key)] = toJsonValue(element)
+		}
+		return asMap
+	case []interface{}:
+		asArray := make([]interface{}, len(v))
+		for i := range v {
+			asArray[i] = toJsonValue(v[i])
+		}
+		return asArray
+	}
+	return value
+}
+
+func (conf *Configuration) addConfiguration(name string, yaml *simpleyaml.Yaml) {
 	if name == HEADERS {
 		headers, _ := yaml.Get(name).Array()
-		for i := range headers {
-			conf.GlobalHeaders[headers[i].(string)] = true
-		}
+		conf.GlobalHeaders = mergeHeaders(conf.GlobalHeaders, asStrings(headers))
 	} else if name == URL {
-		if conf.GlobalUrl == "" {
-			conf.GlobalUrl, _ = yaml.Get(name).String()
-		}
+		conf.GlobalUrl, _ = yaml.Get(name).String()
 	} else if name == OPTIONS {
 		options, _ := yaml.Get(name).Array()
-		for i := range options {
-			conf.GlobalOptions[options[i].(string)] = true
-		}
-	} else if name == FILES {
-		files, _ := yaml.Get(name).Array()
-		return files, nil
+		conf.GlobalOptions = mergeOptions(conf.GlobalOptions, asStrings(options))
+	} else if name == RUNNER {
+		conf.Runner, _ = yaml.Get(name).String()
+	} else if name == HISTORY {
+		conf.HistoryLimit, _ = yaml.Get(name).Int()
 	} else if name == VARIABLES {
 		variables, _ := yaml.Get(name).Map()
 		for i := range variables {
 			conf.GlobalVariables[i.(string)] = variables[i]
 		}
 	}
+}
+
+// mergeHeaders adds headers, in order, after the base ones. A header replaces the base
+// headers with the same name, unless it starts with '+' which keeps them, e.g. to send
+// several cookies. Headers with the same name at the same level are all kept.
+func mergeHeaders(base []string, headers []string) []string {
+	overridden := make(map[string]bool)
+	for _, header := range headers {
+		if !strings.HasPrefix(header, "+") {
+			overridden[headerName(header)] = true
+		}
+	}
+
+	merged := make([]string, 0, len(base)+len(headers))
+	for _, header := range base {
+		if !overridden[headerName(header)] {
+			merged = append(merged, header)
+		}
+	}
+	for _, header := range headers {
+		merged = append(merged, strings.TrimPrefix(header, "+"))
+	}
+	return merged
+}
+
+// headerName returns the lower case name of a 'Name: value' header.
+func headerName(header string) string {
+	header = strings.TrimPrefix(header, "+")
+	if i := strings.IndexAny(header, ":;"); i >= 0 {
+		header = header[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(header))
+}
 
-	return nil, nil
+// mergeOptions adds options, in order, after the base ones, dropping repeated options.
+func mergeOptions(base []string, options []string) []string {
+	merged := make([]string, 0, len(base)+len(options))
+	seen := make(map[string]bool)
+	for _, option := range append(append([]string(nil), base...), options...) {
+		if !seen[option] {
+			seen[option] = true
+			merged = append(merged, option)
+		}
+	}
+	return merged
+}
+
+func asStrings(values []interface{}) []string {
+	asStrings := make([]string, len(values))
+	for i := range values {
+		asStrings[i] = fmt.Sprint(values[i])
+	}
+	return asStrings
 }
 
 func (conf *Configuration) isConfiguration(name string) bool {
-	return name == HEADERS || name == URL || name == OPTIONS || name == FILES || name == VARIABLES
+	return name == HEADERS || name == URL || name == OPTIONS || name == FILES || name == VARIABLES || name == RUNNER || name == HISTORY
 }
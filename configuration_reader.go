@@ -13,8 +13,12 @@ type ConfigurationReader struct {
 	directory      string
 	file           string
 	configurations map[string][]byte
+	dotEnv         map[string]string
 }
 
+// DOT_ENV is an optional file in the yaml directory with environment variables.
+const DOT_ENV = ".env"
+
 func NewDefaultConfigurationReader(directory string, file string) *ConfigurationReader {
 	return NewConfigurationReader(os.Stdout, directory, file)
 }
@@ -29,11 +33,15 @@ func NewConfigurationReader(writer io.Writer, directory string, file string) *Co
 		directory:      directory,
 		file:           file,
 		configurations: make(map[string][]byte),
+		dotEnv:         make(map[string]string),
 	}
 	return confReader
 }
 
 func (confReader *ConfigurationReader) Read() error {
+	if err := confReader.loadDotEnv(); err != nil {
+		return err
+	}
 	return confReader.loadConfigurationAndEndpoints()
 }
 
@@ -45,6 +53,37 @@ func (confReader *ConfigurationReader) Directory() string {
 	return confReader.directory
 }
 
+func (confReader *ConfigurationReader) DotEnv() map[string]string {
+	return confReader.dotEnv
+}
+
+// loadDotEnv reads KEY=value lines, ignoring comments and an optional 'export' prefix.
+func (confReader *ConfigurationReader) loadDotEnv() error {
+	source, err := ioutil.ReadFile(confReader.directory + "/" + DOT_ENV)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	for _, line := range strings.Split(string(source), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		i := strings.Index(line, "=")
+		if i <= 0 {
+			continue
+		}
+		value := strings.TrimSpace(line[i+1:])
+		if len(value) > 1 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		confReader.dotEnv[strings.TrimSpace(line[:i])] = value
+	}
+	return nil
+}
+
 func (confReader *ConfigurationReader) loadConfigurationAndEndpoints() error {
 	if !strings.HasSuffix(confReader.file, ".yaml") {
 		confReader.file = confReader.file + ".yaml"
@@ -37,3 +37,16 @@ func TestOptionalYamlConfigurationReader(t *testing.T) {
 		t.Error("Should have read 1 configuration file")
 	}
 }
+
+func TestLoadDotEnvConfigurationReader(t *testing.T) {
+	confReader := NewSilentConfigurationReader("_resources/valid", "api-requests.yaml")
+
+	if err := confReader.Read(); err != nil {
+		t.Errorf("Should not throw an error '%v'", err)
+	}
+
+	dotEnv := confReader.DotEnv()
+	if len(dotEnv) != 2 || dotEnv["GOHIT_TOKEN"] != "dotenv-token" || dotEnv["GOHIT_QUOTED"] != "quoted value" {
+		t.Errorf("Should have read 2 variables from .env but got %v", dotEnv)
+	}
+}
@@ -2,6 +2,9 @@ package main
 
 import (
 	"errors"
+	"os"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -193,6 +196,156 @@ func TestOverrideUrl(t *testing.T) {
 	}
 }
 
+func TestLoadBody(t *testing.T) {
+	conf, err := NewConfiguration(NewSilentConfigurationReader("_resources/valid", "api-body.yaml"))
+	if err != nil {
+		t.Errorf("Should not throw an error '%v'", err)
+		return
+	}
+
+	if body := conf.Endpoints["create_inline"].Body; body != "name={name}" {
+		t.Errorf("Inline body should be kept as is but got %v", body)
+	}
+
+	if body := conf.Endpoints["create_structured"].Body; body != `{"name":"{name}","tags":["curl","yaml"]}` {
+		t.Errorf("Structured body should be serialized as json but got %v", body)
+	}
+
+	if body := conf.Endpoints["create_file"].Body; body != "{\n  \"name\": \"{name}\"\n}\n" {
+		t.Errorf("File body should be read from body.json but got %v", body)
+	}
+
+	if body := conf.Requests["create_override"].Body; body != "id={id}&name=request" {
+		t.Errorf("Request body should override the endpoint body but got %v", body)
+	}
+}
+
+func TestBodyFileNotFound(t *testing.T) {
+	reader := &MockReader{configurations: make(map[string][]byte)}
+	reader.configurations["test"] = []byte(
+		`
+url: local
+
+endpoints:
+  test:
+    path: /test
+    body: '@missing.json'
+`)
+
+	if _, err := NewConfiguration(reader); err == nil || !strings.HasPrefix(err.Error(), "Endpoint 'test' has an invalid body") {
+		t.Error("Should have thrown an invalid body error but got ", err)
+	}
+}
+
+func TestEnvironmentVariablePlaceholders(t *testing.T) {
+	os.Setenv("GOHIT_TEST_USER", "process-user")
+	defer os.Unsetenv("GOHIT_TEST_USER")
+
+	reader := &MockReader{
+		configurations: make(map[string][]byte),
+		dotEnv:         map[string]string{"GOHIT_TEST_USER": "dotenv-user", "GOHIT_TEST_TOKEN": "dotenv-token"},
+	}
+	reader.configurations["test"] = []byte(
+		`
+url: local
+
+headers:
+  - 'Authorization: bearer ${GOHIT_TEST_TOKEN}'
+
+variables:
+  user: '{env:GOHIT_TEST_USER}'
+
+endpoints:
+  test:
+    path: /test/{user}/{env:GOHIT_TEST_MISSING}
+
+requests:
+  my_request:
+    endpoint: test
+`)
+
+	conf, err := NewConfiguration(reader)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	request := conf.Requests["my_request"]
+	if request.Path != "/test/process-user/{env:GOHIT_TEST_MISSING}" || !containsString(request.Headers, "Authorization: bearer dotenv-token") {
+		t.Errorf("Request configuration problem %v %v", request, request.Headers)
+	}
+}
+
+func TestHeadersAndOptionsOrder(t *testing.T) {
+	reader := &MockReader{configurations: make(map[string][]byte)}
+	reader.configurations["test"] = []byte(
+		`
+url: local
+
+headers:
+  - 'Accept: application/json'
+  - 'Cookie: a=1'
+  - 'Cookie: b=2'
+
+options:
+  - '-u user:pass'
+  - '--compress'
+
+endpoints:
+  test:
+    path: /test
+    headers:
+      - 'accept: application/xml'
+      - '+Cookie: c=3'
+    options:
+      - '--compress'
+      - '--data name=gohit'
+
+requests:
+  my_request:
+    endpoint: test
+    headers:
+      - 'Cookie: d=4'
+      - 'X-Request: 1'
+    options:
+      - '-k'
+`)
+
+	conf, err := NewConfiguration(reader)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	endpoint := conf.Endpoints["test"]
+	if !reflect.DeepEqual(endpoint.Headers, []string{"Cookie: a=1", "Cookie: b=2", "accept: application/xml", "Cookie: c=3"}) {
+		t.Errorf("Endpoint headers problem %v", endpoint.Headers)
+	}
+	if !reflect.DeepEqual(endpoint.Options, []string{"-u user:pass", "--compress", "--data name=gohit"}) {
+		t.Errorf("Endpoint options problem %v", endpoint.Options)
+	}
+
+	request := conf.Requests["my_request"]
+	if !reflect.DeepEqual(request.Headers, []string{"accept: application/xml", "Cookie: d=4", "X-Request: 1"}) {
+		t.Errorf("Request headers problem %v", request.Headers)
+	}
+	if !reflect.DeepEqual(request.Options, []string{"-u user:pass", "--compress", "--data name=gohit", "-k"}) {
+		t.Errorf("Request options problem %v", request.Options)
+	}
+}
+
+func TestImportedHeadersOverridden(t *testing.T) {
+	conf, _ := NewConfiguration(NewSilentConfigurationReader("_resources/valid", "api-requests2.yaml"))
+
+	if !reflect.DeepEqual(conf.GlobalHeaders, []string{"Accept: application/vnd.github.v3+json", "Authorization: bearer a12b3c", "Custom: value"}) {
+		t.Errorf("Imported headers should be declared first %v", conf.GlobalHeaders)
+	}
+
+	if !reflect.DeepEqual(conf.GlobalOptions, []string{"--compress", "-vvv", "--silent", "-s"}) {
+		t.Errorf("Imported options should be declared first %v", conf.GlobalOptions)
+	}
+}
+
 func TestLoadMissingEndpoints(t *testing.T) {
 	reader := &MockReader{configurations: make(map[string][]byte)}
 	reader.configurations["test"] = []byte(
@@ -274,6 +427,45 @@ endpoints:
 	}
 }
 
+func TestVariableNamedLikeAttribute(t *testing.T) {
+	reader := &MockReader{configurations: make(map[string][]byte)}
+	reader.configurations["test"] = []byte(`
+url: local
+
+endpoints:
+  test:
+    path: /test/{data}
+    headers:
+      - 'Accept: {body}'
+
+requests:
+  my_request:
+    endpoint: test
+    data: x
+`)
+
+	message := "Request my_request uses {data} but 'data' is a request attribute, not a variable: rename the variable"
+	if _, err := NewConfiguration(reader); err == nil || err.Error() != message {
+		t.Errorf("Should have thrown '%v' but got %v", message, err)
+	}
+
+	reader.configurations["test"] = []byte(`
+url: local
+
+endpoints:
+  test:
+    path: /test/{data}
+
+requests:
+  my_request:
+    endpoint: test
+    body: x
+`)
+	if _, err := NewConfiguration(reader); err != nil {
+		t.Error("Should not throw an error when the attribute isn't used as a variable ", err)
+	}
+}
+
 func TestFileNotFound(t *testing.T) {
 	reader := &MockReader{configurations: make(map[string][]byte), errorWhenReading: errors.New("Test error")}
 
@@ -294,7 +486,21 @@ func (reader *MockReader) Directory() string {
 	return "test"
 }
 
+func (reader *MockReader) DotEnv() map[string]string {
+	return reader.dotEnv
+}
+
+func containsString(list []string, value string) bool {
+	for i := range list {
+		if list[i] == value {
+			return true
+		}
+	}
+	return false
+}
+
 type MockReader struct {
 	configurations   map[string][]byte
+	dotEnv           map[string]string
 	errorWhenReading error
 }
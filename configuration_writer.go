@@ -0,0 +1,147 @@
+package main
+
+import (
+	"io"
+	"sort"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ConfigurationWriter renders endpoints and requests back as yaml, in the
+// structure read by addEndpoint and createRequest. Used by the import commands.
+type ConfigurationWriter struct {
+	Url          string
+	Headers      []string
+	Variables    map[string]interface{}
+	Environments []*Environment
+	Endpoints    []*Endpoint
+	Requests     []*Request
+}
+
+func (writer *ConfigurationWriter) Write(out io.Writer) error {
+	document := yaml.MapSlice{}
+	if writer.Url != "" {
+		document = append(document, yaml.MapItem{Key: URL, Value: writer.Url})
+	}
+	if len(writer.Headers) > 0 {
+		document = append(document, yaml.MapItem{Key: HEADERS, Value: writer.Headers})
+	}
+	if len(writer.Variables) > 0 {
+		document = append(document, yaml.MapItem{Key: VARIABLES, Value: sortedMapSlice(writer.Variables)})
+	}
+	if len(writer.Environments) > 0 {
+		environments := yaml.MapSlice{}
+		for _, environment := range writer.Environments {
+			environments = append(environments, yaml.MapItem{Key: environment.Name, Value: environmentAsYaml(environment)})
+		}
+		document = append(document, yaml.MapItem{Key: ENVIRONMENTS, Value: environments})
+	}
+	if len(writer.Endpoints) > 0 {
+		endpoints := yaml.MapSlice{}
+		for _, endpoint := range writer.Endpoints {
+			endpoints = append(endpoints, yaml.MapItem{Key: endpoint.Name, Value: endpointAsYaml(endpoint)})
+		}
+		document = append(document, yaml.MapItem{Key: ENDPOINTS, Value: endpoints})
+	}
+	if len(writer.Requests) > 0 {
+		requests := yaml.MapSlice{}
+		for _, request := range writer.Requests {
+			requests = append(requests, yaml.MapItem{Key: request.Name, Value: requestAsYaml(request)})
+		}
+		document = append(document, yaml.MapItem{Key: REQUESTS, Value: requests})
+	}
+
+	source, err := yaml.Marshal(document)
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(source)
+	return err
+}
+
+func environmentAsYaml(environment *Environment) yaml.MapSlice {
+	definition := yaml.MapSlice{}
+	if environment.Url != "" {
+		definition = append(definition, yaml.MapItem{Key: URL, Value: environment.Url})
+	}
+	if len(environment.Headers) > 0 {
+		definition = append(definition, yaml.MapItem{Key: HEADERS, Value: environment.Headers})
+	}
+	if len(environment.Options) > 0 {
+		definition = append(definition, yaml.MapItem{Key: OPTIONS, Value: environment.Options})
+	}
+	if len(environment.Variables) > 0 {
+		definition = append(definition, yaml.MapItem{Key: VARIABLES, Value: sortedMapSlice(environment.Variables)})
+	}
+	return definition
+}
+
+func endpointAsYaml(endpoint *Endpoint) yaml.MapSlice {
+	definition := yaml.MapSlice{}
+	if endpoint.Url != "" {
+		definition = append(definition, yaml.MapItem{Key: URL, Value: endpoint.Url})
+	}
+	definition = append(definition, yaml.MapItem{Key: PATH, Value: endpoint.Path})
+	if endpoint.Method != "" && endpoint.Method != "GET" {
+		definition = append(definition, yaml.MapItem{Key: METHOD, Value: endpoint.Method})
+	}
+	if endpoint.QueryRaw != "" {
+		definition = append(definition, yaml.MapItem{Key: QUERY, Value: endpoint.QueryRaw})
+	} else if len(endpoint.QueryListKeys) > 0 {
+		query := make([]interface{}, 0, len(endpoint.QueryListKeys))
+		for _, key := range endpoint.QueryListKeys {
+			if endpoint.QueryList[key] == "{"+key+"}" {
+				query = append(query, key)
+			} else {
+				query = append(query, yaml.MapSlice{{Key: key, Value: endpoint.QueryList[key]}})
+			}
+		}
+		definition = append(definition, yaml.MapItem{Key: QUERY, Value: query})
+	}
+	if len(endpoint.Headers) > 0 {
+		definition = append(definition, yaml.MapItem{Key: HEADERS, Value: endpoint.Headers})
+	}
+	if len(endpoint.Options) > 0 {
+		definition = append(definition, yaml.MapItem{Key: OPTIONS, Value: endpoint.Options})
+	}
+	if endpoint.Body != "" {
+		definition = append(definition, yaml.MapItem{Key: BODY, Value: endpoint.Body})
+	}
+	if len(endpoint.Parameters) > 0 {
+		definition = append(definition, yaml.MapItem{Key: PARAMETERS, Value: sortedMapSlice(endpoint.Parameters)})
+	}
+	return definition
+}
+
+// requestAsYaml writes the request parameters, starting with its endpoint.
+func requestAsYaml(request *Request) yaml.MapSlice {
+	definition := yaml.MapSlice{}
+	if endpoint, ok := request.Parameters[ENDPOINT]; ok {
+		definition = append(definition, yaml.MapItem{Key: ENDPOINT, Value: endpoint})
+	}
+	parameters := make(map[string]interface{}, len(request.Parameters))
+	for key, value := range request.Parameters {
+		if key != ENDPOINT {
+			parameters[key.(string)] = value
+		}
+	}
+	return append(definition, sortedMapSlice(parameters)...)
+}
+
+func sortedKeys(values map[string]interface{}) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedMapSlice(values map[string]interface{}) yaml.MapSlice {
+	keys := sortedKeys(values)
+	slice := make(yaml.MapSlice, 0, len(keys))
+	for _, key := range keys {
+		slice = append(slice, yaml.MapItem{Key: key, Value: values[key]})
+	}
+	return slice
+}
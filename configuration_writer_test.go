@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteConfiguration(t *testing.T) {
+	writer := &ConfigurationWriter{
+		Url:       "http://localhost",
+		Headers:   []string{"Accept: application/json"},
+		Variables: map[string]interface{}{"version": 2, "name": "gohit"},
+		Endpoints: []*Endpoint{
+			{
+				Name:          "search",
+				Path:          "/search",
+				Method:        "GET",
+				QueryList:     map[string]string{"q": "{q}", "page": "1"},
+				QueryListKeys: []string{"q", "page"},
+			},
+			{
+				Name:    "create",
+				Path:    "/items",
+				Method:  "POST",
+				Options: []string{"-k"},
+				Body:    `{"name":"{name}"}`,
+			},
+		},
+		Requests: []*Request{
+			{Name: "search_gohit", Parameters: map[interface{}]interface{}{"q": "gohit", ENDPOINT: "search"}},
+		},
+	}
+
+	var b bytes.Buffer
+	if err := writer.Write(&b); err != nil {
+		t.Error(err)
+		return
+	}
+
+	expected := `url: http://localhost
+headers:
+- 'Accept: application/json'
+variables:
+  name: gohit
+  version: 2
+endpoints:
+  search:
+    path: /search
+    query:
+    - q
+    - page: "1"
+  create:
+    path: /items
+    method: POST
+    options:
+    - -k
+    body: '{"name":"{name}"}'
+requests:
+  search_gohit:
+    endpoint: search
+    q: gohit
+`
+	if b.String() != expected {
+		t.Errorf("Unexpected yaml\n%v", b.String())
+	}
+}
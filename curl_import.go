@@ -0,0 +1,359 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// curl options followed by a value, the ones not translated are kept as endpoint options.
+var curlOptionsWithValue = map[string]bool{
+	"-X": true, "--request": true, "--url": true,
+	"-H": true, "--header": true,
+	"-d": true, "--data": true, "--data-raw": true, "--data-binary": true, "--data-ascii": true, "--data-urlencode": true, "--json": true,
+	"-A": true, "--user-agent": true,
+	"-b": true, "--cookie": true,
+	"-e": true, "--referer": true,
+	"-u": true, "--user": true,
+	"-m": true, "--max-time": true, "--connect-timeout": true,
+	"-o": true, "--output": true,
+	"-x": true, "--proxy": true,
+	"-F": true, "--form": true,
+	"-E": true, "--cert": true, "--cacert": true, "--key": true,
+	"--retry": true, "--resolve": true,
+	"-w": true, "--write-out": true,
+}
+
+// curl options that are always sent as a header.
+var curlHeaderOptions = map[string]string{
+	"-A": "User-Agent", "--user-agent": "User-Agent",
+	"-b": "Cookie", "--cookie": "Cookie",
+	"-e": "Referer", "--referer": "Referer",
+}
+
+var nonNameCharacters = regexp.MustCompile(`[^a-z0-9]+`)
+
+// ImportCurl translates a curl command line into an endpoint and a request using it.
+// The endpoint is named after the method and path unless a name is given.
+func ImportCurl(args []string, name string) (*ConfigurationWriter, error) {
+	if len(args) > 0 && args[0] == "curl" {
+		args = args[1:]
+	}
+
+	endpoint := &Endpoint{Parameters: make(map[string]interface{})}
+	var rawUrl string
+	var data []*curlData
+	get, user := false, false
+	for i := 0; i < len(args); i++ {
+		option, value := args[i], ""
+		if !strings.HasPrefix(option, "-") {
+			rawUrl = option
+			continue
+		}
+		if curlOptionsWithValue[option] {
+			if i+1 == len(args) {
+				return nil, errors.New(fmt.Sprintf("Missing value for curl option '%v'", option))
+			}
+			i++
+			value = args[i]
+		} else if len(option) > 2 && !strings.HasPrefix(option, "--") && curlOptionsWithValue[option[:2]] {
+			option, value = option[:2], option[2:]
+		}
+
+		switch option {
+		case "-X", "--request":
+			endpoint.Method = strings.ToUpper(value)
+		case "-H", "--header":
+			endpoint.Headers = append(endpoint.Headers, value)
+		case "-d", "--data", "--data-raw", "--data-binary", "--data-ascii":
+			data = append(data, &curlData{raw: value})
+		case "--data-urlencode":
+			urlencoded, err := readDataUrlencode(value)
+			if err != nil {
+				return nil, err
+			}
+			data = append(data, urlencoded)
+		case "--json":
+			data = append(data, &curlData{raw: value})
+			endpoint.Headers = append(endpoint.Headers, "Content-Type: application/json", "Accept: application/json")
+		case "-A", "--user-agent", "-b", "--cookie", "-e", "--referer":
+			endpoint.Headers = append(endpoint.Headers, curlHeaderOptions[option]+": "+value)
+		case "--url":
+			rawUrl = value
+		case "-G", "--get":
+			get = true
+		case "-I", "--head":
+			endpoint.Method = "HEAD"
+		case "-u", "--user":
+			user = true
+			endpoint.Options = append(endpoint.Options, curlOption(option, value))
+		default:
+			endpoint.Options = append(endpoint.Options, curlOption(option, value))
+		}
+	}
+
+	if rawUrl == "" {
+		return nil, errors.New("Missing url in curl command")
+	}
+	parsed, err := url.Parse(rawUrl)
+	if err != nil {
+		return nil, err
+	}
+	if parsed.Scheme == "" || parsed.Host == "" {
+		return nil, errors.New(fmt.Sprintf("Invalid url '%v'", rawUrl))
+	}
+	endpoint.Url = parsed.Scheme + "://" + parsed.Host
+	if parsed.User != nil && !user {
+		// as curl does, a user without a password in the url has an empty one
+		password, _ := parsed.User.Password()
+		endpoint.Options = append(endpoint.Options, curlOption("-u", parsed.User.Username()+":"+password))
+	}
+	endpoint.Path = parsed.EscapedPath()
+	if endpoint.Path == "" {
+		endpoint.Path = "/"
+	}
+	endpoint.QueryRaw = parsed.RawQuery
+
+	if get {
+		if query, ok := curlQueryList(endpoint.QueryRaw, data); ok {
+			endpoint.QueryRaw = ""
+			endpoint.QueryList = make(map[string]string)
+			for _, pair := range query {
+				if _, ok := endpoint.QueryList[pair[0]]; !ok {
+					endpoint.QueryListKeys = append(endpoint.QueryListKeys, pair[0])
+				}
+				endpoint.QueryList[pair[0]] = pair[1]
+			}
+		} else {
+			endpoint.QueryRaw = strings.Trim(strings.Join(append([]string{endpoint.QueryRaw}, curlDataStrings(data)...), "&"), "&")
+		}
+	} else if len(data) > 0 {
+		endpoint.Body = strings.Join(curlDataStrings(data), "&")
+		if endpoint.Method == "" {
+			endpoint.Method = "POST"
+		}
+	}
+	if endpoint.Method == "" {
+		endpoint.Method = "GET"
+	}
+
+	if name == "" {
+		name = nameFromPath(endpoint.Method, endpoint.Path)
+	}
+	endpoint.Name = name
+	request := &Request{
+		Name:       name + "_request",
+		Parameters: map[interface{}]interface{}{ENDPOINT: name},
+	}
+	return &ConfigurationWriter{Endpoints: []*Endpoint{endpoint}, Requests: []*Request{request}}, nil
+}
+
+// curlData is the value of a data option. The --data-urlencode ones are kept as the name
+// and the content curl encodes.
+type curlData struct {
+	raw     string
+	name    string
+	content string
+	encoded bool
+}
+
+// readDataUrlencode reads a --data-urlencode value: 'content', '=content', 'name=content',
+// '@file' or 'name@file'.
+func readDataUrlencode(value string) (*curlData, error) {
+	data := &curlData{content: value, encoded: true}
+	if i := strings.IndexAny(value, "=@"); i >= 0 {
+		data.name, data.content = value[:i], value[i+1:]
+		if value[i] == '@' {
+			source, err := ioutil.ReadFile(data.content)
+			if err != nil {
+				return nil, err
+			}
+			data.content = string(source)
+		}
+	}
+	return data, nil
+}
+
+// String returns the data as curl sends it.
+func (data *curlData) String() string {
+	if !data.encoded {
+		return data.raw
+	}
+	if data.name == "" {
+		return curlEscape(data.content)
+	}
+	return data.name + "=" + curlEscape(data.content)
+}
+
+func curlDataStrings(data []*curlData) []string {
+	values := make([]string, len(data))
+	for i := range data {
+		values[i] = data[i].String()
+	}
+	return values
+}
+
+// curlQueryList returns the names and decoded values of the url query and the -G data, when
+// there is --data-urlencode data and every value has a name, so it can be a query list.
+func curlQueryList(rawQuery string, data []*curlData) ([][2]string, bool) {
+	var query [][2]string
+	urlencoded := false
+	addRaw := func(raw string) bool {
+		for _, pair := range strings.Split(raw, "&") {
+			if pair == "" {
+				continue
+			}
+			parts := strings.SplitN(pair, "=", 2)
+			if len(parts) == 1 || parts[0] == "" {
+				return false
+			}
+			value, err := url.QueryUnescape(parts[1])
+			if err != nil {
+				value = parts[1]
+			}
+			query = append(query, [2]string{parts[0], value})
+		}
+		return true
+	}
+	if !addRaw(rawQuery) {
+		return nil, false
+	}
+	for _, d := range data {
+		if !d.encoded {
+			if !addRaw(d.raw) {
+				return nil, false
+			}
+		} else if d.name != "" {
+			urlencoded = true
+			query = append(query, [2]string{d.name, d.content})
+		} else {
+			return nil, false
+		}
+	}
+	return query, urlencoded
+}
+
+// curlEscape encodes all but the unreserved characters, as curl does.
+func curlEscape(value string) string {
+	var escaped strings.Builder
+	for _, c := range []byte(value) {
+		if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || strings.IndexByte("-._~", c) >= 0 {
+			escaped.WriteByte(c)
+		} else {
+			fmt.Fprintf(&escaped, "%%%02X", c)
+		}
+	}
+	return escaped.String()
+}
+
+func curlOption(option string, value string) string {
+	if value == "" {
+		return option
+	}
+	if strings.ContainsAny(value, " \t") {
+		value = "'" + value + "'"
+	}
+	return option + " " + value
+}
+
+// nameFromPath builds a name like get_repos_owner from a method and a path.
+func nameFromPath(method string, path string) string {
+	name := strings.Trim(nonNameCharacters.ReplaceAllString(strings.ToLower(method+" "+path), "_"), "_")
+	if name == "" {
+		return "request"
+	}
+	return name
+}
+
+// splitCommandLine splits a command line the way a shell would, understanding
+// quotes, escapes, bash $'...' strings and line continuations.
+func splitCommandLine(command string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	runes := []rune(command)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		switch {
+		case c == '\\' && i+1 < len(runes):
+			i++
+			if runes[i] != '\n' && runes[i] != '\r' {
+				current.WriteRune(runes[i])
+				inArg = true
+			} else if runes[i] == '\r' && i+1 < len(runes) && runes[i+1] == '\n' {
+				i++
+			}
+		case c == '\'':
+			end := indexRune(runes, i+1, '\'')
+			if end < 0 {
+				return nil, errors.New("Unterminated quote in command")
+			}
+			current.WriteString(string(runes[i+1 : end]))
+			inArg = true
+			i = end
+		case c == '$' && i+1 < len(runes) && runes[i+1] == '\'':
+			i += 2
+			for ; i < len(runes) && runes[i] != '\''; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+					current.WriteString(unescapeAnsiC(runes[i]))
+				} else {
+					current.WriteRune(runes[i])
+				}
+			}
+			if i == len(runes) {
+				return nil, errors.New("Unterminated quote in command")
+			}
+			inArg = true
+		case c == '"':
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) && strings.ContainsRune("\"\\$`\n", runes[i+1]) {
+					i++
+				}
+				current.WriteRune(runes[i])
+			}
+			if i == len(runes) {
+				return nil, errors.New("Unterminated quote in command")
+			}
+			inArg = true
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(c)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+func indexRune(runes []rune, from int, r rune) int {
+	for i := from; i < len(runes); i++ {
+		if runes[i] == r {
+			return i
+		}
+	}
+	return -1
+}
+
+func unescapeAnsiC(r rune) string {
+	switch r {
+	case 'n':
+		return "\n"
+	case 't':
+		return "\t"
+	case 'r':
+		return "\r"
+	}
+	return string(r)
+}
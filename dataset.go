@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Dataset holds the rows a request runs with, each column binding the {placeholder} of its name.
+type Dataset struct {
+	Columns []string
+	Rows    []map[interface{}]interface{}
+}
+
+// ReadDataset reads a csv file with a header line or a json file with an array of objects.
+func ReadDataset(file string) (*Dataset, error) {
+	source, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var dataset *Dataset
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".csv":
+		dataset, err = readCsvDataset(source)
+	case ".json":
+		dataset, err = readJsonDataset(source)
+	default:
+		return nil, errors.New(fmt.Sprintf("Data file %v should be a .csv or .json file", file))
+	}
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Could not read data file %v: %v", file, err))
+	}
+	if len(dataset.Rows) == 0 {
+		return nil, errors.New(fmt.Sprintf("Data file %v has no rows", file))
+	}
+	return dataset, nil
+}
+
+func readCsvDataset(source []byte) (*Dataset, error) {
+	records, err := csv.NewReader(bytes.NewReader(source)).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return &Dataset{}, nil
+	}
+	dataset := &Dataset{Columns: records[0]}
+	for _, record := range records[1:] {
+		row := make(map[interface{}]interface{}, len(record))
+		for i, value := range record {
+			row[dataset.Columns[i]] = value
+		}
+		dataset.Rows = append(dataset.Rows, row)
+	}
+	return dataset, nil
+}
+
+func readJsonDataset(source []byte) (*Dataset, error) {
+	decoder := json.NewDecoder(bytes.NewReader(source))
+	// numbers are kept as written, so big ids aren't rounded
+	decoder.UseNumber()
+	var objects []map[string]interface{}
+	if err := decoder.Decode(&objects); err != nil {
+		return nil, err
+	}
+
+	dataset := &Dataset{}
+	seen := make(map[string]bool)
+	for i, object := range objects {
+		row := make(map[interface{}]interface{}, len(object))
+		for column, value := range object {
+			switch v := value.(type) {
+			case string, bool:
+				row[column] = v
+			case json.Number:
+				row[column] = v.String()
+			case nil:
+				row[column] = ""
+			default:
+				return nil, errors.New(fmt.Sprintf("row %v has a nested value for '%v'", i+1, column))
+			}
+			if !seen[column] {
+				seen[column] = true
+				dataset.Columns = append(dataset.Columns, column)
+			}
+		}
+		dataset.Rows = append(dataset.Rows, row)
+	}
+	sort.Strings(dataset.Columns)
+	return dataset, nil
+}
+
+// Label describes a row by its values, in the order of the columns.
+func (dataset *Dataset) Label(conf *Configuration, index int) string {
+	row := dataset.Rows[index]
+	var values []string
+	for _, column := range dataset.Columns {
+		if value, ok := row[column]; ok {
+			values = append(values, column+"="+conf.getReplacement(value))
+		}
+	}
+	return fmt.Sprintf("#%v %v", index+1, strings.Join(values, " "))
+}
+
+// Dataset returns the rows a request runs with, read from the file when given or else from
+// the request's data attribute, relative to the configuration directory. It returns nil
+// when there are none.
+func (executor *Executor) Dataset(requestName string, file string) (*Dataset, error) {
+	if file == "" {
+		request := executor.conf.Requests[requestName]
+		if request == nil || request.Data == "" {
+			return nil, nil
+		}
+		file = request.Data
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(executor.conf.reader.Directory(), file)
+		}
+	}
+	return ReadDataset(file)
+}
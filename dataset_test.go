@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadDataset(t *testing.T) {
+	directory, _ := ioutil.TempDir("", "gohit")
+	defer os.RemoveAll(directory)
+
+	csvFile := filepath.Join(directory, "users.csv")
+	ioutil.WriteFile(csvFile, []byte("id,name\n1,anna\n2,\"bob, jr\"\n"), 0644)
+	dataset, err := ReadDataset(csvFile)
+	if err != nil {
+		t.Error("Should not throw an error ", err)
+		return
+	}
+	expected := []map[interface{}]interface{}{{"id": "1", "name": "anna"}, {"id": "2", "name": "bob, jr"}}
+	if !reflect.DeepEqual(dataset.Columns, []string{"id", "name"}) || !reflect.DeepEqual(dataset.Rows, expected) {
+		t.Errorf("Unexpected csv dataset %v %v", dataset.Columns, dataset.Rows)
+	}
+
+	jsonFile := filepath.Join(directory, "users.json")
+	ioutil.WriteFile(jsonFile, []byte(`[{"name": "anna", "id": 12345678901234567}, {"id": 2, "admin": true, "name": null}]`), 0644)
+	dataset, err = ReadDataset(jsonFile)
+	if err != nil {
+		t.Error("Should not throw an error ", err)
+		return
+	}
+	expected = []map[interface{}]interface{}{{"id": "12345678901234567", "name": "anna"}, {"id": "2", "admin": true, "name": ""}}
+	if !reflect.DeepEqual(dataset.Columns, []string{"admin", "id", "name"}) || !reflect.DeepEqual(dataset.Rows, expected) {
+		t.Errorf("Unexpected json dataset %v %v", dataset.Columns, dataset.Rows)
+	}
+
+	for file, content := range map[string]string{
+		"users.json": `[{"id": {"nested": 1}}]`,
+		"empty.csv":  "id,name\n",
+		"users.txt":  "1",
+	} {
+		ioutil.WriteFile(filepath.Join(directory, file), []byte(content), 0644)
+	}
+	for file, message := range map[string]string{
+		"users.json": "Could not read data file " + jsonFile + ": row 1 has a nested value for 'id'",
+		"empty.csv":  "Data file " + filepath.Join(directory, "empty.csv") + " has no rows",
+		"users.txt":  "Data file " + filepath.Join(directory, "users.txt") + " should be a .csv or .json file",
+	} {
+		if _, err := ReadDataset(filepath.Join(directory, file)); err == nil || err.Error() != message {
+			t.Errorf("Should have thrown '%v' but got %v", message, err)
+		}
+	}
+}
+
+func TestRunData(t *testing.T) {
+	directory, _ := ioutil.TempDir("", "gohit")
+	defer os.RemoveAll(directory)
+	file := filepath.Join(directory, "users.csv")
+	ioutil.WriteFile(file, []byte("id,fields\n1,name\nmissing,id\n"), 0644)
+
+	reader := &MockReader{configurations: make(map[string][]byte)}
+	reader.configurations["test"] = []byte(`
+url: local
+
+endpoints:
+  get_user:
+    path: /users/{id}
+    query:
+      - fields
+
+requests:
+  each_user:
+    endpoint: get_user
+    fields: all
+    data: ` + file + `
+`)
+	conf, err := NewConfiguration(reader)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	executor := NewExecutor(conf, &PathMockRunner{}, &MockVariableReader{})
+
+	var out, summary bytes.Buffer
+	output, _ := NewResponsePrinter(&out, OUTPUT_RAW, COLOR_NEVER, nil)
+	failed := NewBatchRunner(executor, output, &summary, 2).Run([]string{"each_user"})
+	if failed != 1 {
+		t.Errorf("Should have 1 failed row but got %v", failed)
+	}
+	expected := "#### each_user #1 id=1 fields=name ####\n/users/1?fields=name\n#### each_user #2 id=missing fields=id ####\n/users/missing?fields=id\n"
+	if out.String() != expected {
+		t.Errorf("Should have printed the rows in order but got %v", out.String())
+	}
+	if !strings.HasPrefix(summary.String(), "\nFAIL each_user #2 id=missing fields=id\n    status 404\n1 succeeded, 1 failed (") {
+		t.Errorf("Unexpected summary %v", summary.String())
+	}
+
+	dataset, err := executor.Dataset("get_user", file)
+	if err != nil {
+		t.Error("Should not throw an error ", err)
+		return
+	}
+	out.Reset()
+	summary.Reset()
+	if failed := NewBatchRunner(executor, output, &summary, 1).RunData("get_user", dataset, nil); failed != 1 {
+		t.Errorf("Should have 1 failed row but got %v", failed)
+	}
+	if !strings.Contains(out.String(), "#### get_user #1 id=1 fields=name ####\n/users/1?fields=name\n") {
+		t.Errorf("Unexpected output %v", out.String())
+	}
+
+	if dataset, err := executor.Dataset("get_user", ""); dataset != nil || err != nil {
+		t.Errorf("Should not have a dataset without a data file but got %v %v", dataset, err)
+	}
+}
+
+// PathMockRunner answers with the path of the request, with a 404 when it contains 'missing'.
+type PathMockRunner struct {
+}
+
+func (runner *PathMockRunner) Run(request *Request, command []string) (*Response, error) {
+	path := strings.TrimPrefix(requestUrl(request), request.Url)
+	if strings.Contains(path, "missing") {
+		return &Response{StatusCode: 404, Body: []byte(path)}, nil
+	}
+	return &Response{StatusCode: 200, Body: []byte(path)}, nil
+}
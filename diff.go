@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	DIFF_ADDED   = "+"
+	DIFF_REMOVED = "-"
+	DIFF_CHANGED = "~"
+
+	// diffValueLength is how much of a value is shown in a difference.
+	diffValueLength = 80
+)
+
+// Difference is a value that isn't the same in two responses, at a path like '$.items[0].name'.
+type Difference struct {
+	Kind  string
+	Path  string
+	Left  interface{}
+	Right interface{}
+}
+
+func (difference *Difference) String() string {
+	switch difference.Kind {
+	case DIFF_ADDED:
+		return fmt.Sprintf("+ %v: %v", difference.Path, diffValue(difference.Right))
+	case DIFF_REMOVED:
+		return fmt.Sprintf("- %v: %v", difference.Path, diffValue(difference.Left))
+	}
+	return fmt.Sprintf("~ %v: %v -> %v", difference.Path, diffValue(difference.Left), diffValue(difference.Right))
+}
+
+// DiffResponses compares the status and the bodies of two responses, json bodies structurally.
+// Ignored paths, like '$.updated_at' or 'items[*].id', and everything below them aren't compared.
+func DiffResponses(left *Response, right *Response, ignore []string) []*Difference {
+	var differences []*Difference
+	if left.StatusCode != right.StatusCode {
+		differences = append(differences, &Difference{Kind: DIFF_CHANGED, Path: "status", Left: left.StatusCode, Right: right.StatusCode})
+	}
+
+	var leftJson, rightJson interface{}
+	if decodeJson(left.Body, &leftJson) == nil && decodeJson(right.Body, &rightJson) == nil {
+		return append(differences, DiffJson(leftJson, rightJson, ignore)...)
+	}
+	if !bytes.Equal(left.Body, right.Body) {
+		leftLines := strings.Split(string(left.Body), "\n")
+		rightLines := strings.Split(string(right.Body), "\n")
+		for i := 0; i < len(leftLines) || i < len(rightLines); i++ {
+			if i >= len(leftLines) {
+				return append(differences, &Difference{Kind: DIFF_ADDED, Path: "body line " + strconv.Itoa(i+1), Right: rightLines[i]})
+			} else if i >= len(rightLines) {
+				return append(differences, &Difference{Kind: DIFF_REMOVED, Path: "body line " + strconv.Itoa(i+1), Left: leftLines[i]})
+			} else if leftLines[i] != rightLines[i] {
+				// only the first different line of bodies that aren't json
+				return append(differences, &Difference{Kind: DIFF_CHANGED, Path: "body line " + strconv.Itoa(i+1), Left: leftLines[i], Right: rightLines[i]})
+			}
+		}
+	}
+	return differences
+}
+
+// decodeJson decodes a json document keeping the numbers as written, so big ids aren't rounded.
+// Like json.Unmarshal it fails when there is anything after the document.
+func decodeJson(source []byte, value interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(source))
+	decoder.UseNumber()
+	if err := decoder.Decode(value); err != nil {
+		return err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return errors.New("invalid character after top-level value")
+	}
+	return nil
+}
+
+// equalNumbers compares two json numbers exactly, whatever way they are written.
+func equalNumbers(left json.Number, right json.Number) bool {
+	if left == right {
+		return true
+	}
+	leftValue, leftOk := new(big.Rat).SetString(string(left))
+	rightValue, rightOk := new(big.Rat).SetString(string(right))
+	return leftOk && rightOk && leftValue.Cmp(rightValue) == 0
+}
+
+// DiffJson compares two decoded json documents, returning the differences sorted by path.
+func DiffJson(left interface{}, right interface{}, ignore []string) []*Difference {
+	var ignored [][]string
+	for _, path := range ignore {
+		ignored = append(ignored, splitJsonPath(path))
+	}
+	var differences []*Difference
+	diffJsonValue("$", nil, left, right, ignored, &differences)
+	return differences
+}
+
+func diffJsonValue(path string, keys []string, left interface{}, right interface{}, ignored [][]string, differences *[]*Difference) {
+	if isIgnoredPath(keys, ignored) {
+		return
+	}
+	switch leftValue := left.(type) {
+	case map[string]interface{}:
+		if rightValue, ok := right.(map[string]interface{}); ok {
+			names := make([]string, 0, len(leftValue)+len(rightValue))
+			for name := range leftValue {
+				names = append(names, name)
+			}
+			for name := range rightValue {
+				if _, ok := leftValue[name]; !ok {
+					names = append(names, name)
+				}
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				childPath := path + "." + name
+				childKeys := append(append([]string(nil), keys...), name)
+				leftChild, inLeft := leftValue[name]
+				rightChild, inRight := rightValue[name]
+				if !inRight {
+					if !isIgnoredPath(childKeys, ignored) {
+						*differences = append(*differences, &Difference{Kind: DIFF_REMOVED, Path: childPath, Left: leftChild})
+					}
+				} else if !inLeft {
+					if !isIgnoredPath(childKeys, ignored) {
+						*differences = append(*differences, &Difference{Kind: DIFF_ADDED, Path: childPath, Right: rightChild})
+					}
+				} else {
+					diffJsonValue(childPath, childKeys, leftChild, rightChild, ignored, differences)
+				}
+			}
+			return
+		}
+	case []interface{}:
+		if rightValue, ok := right.([]interface{}); ok {
+			for i := 0; i < len(leftValue) || i < len(rightValue); i++ {
+				childPath := path + "[" + strconv.Itoa(i) + "]"
+				childKeys := append(append([]string(nil), keys...), strconv.Itoa(i))
+				if i >= len(rightValue) {
+					if !isIgnoredPath(childKeys, ignored) {
+						*differences = append(*differences, &Difference{Kind: DIFF_REMOVED, Path: childPath, Left: leftValue[i]})
+					}
+				} else if i >= len(leftValue) {
+					if !isIgnoredPath(childKeys, ignored) {
+						*differences = append(*differences, &Difference{Kind: DIFF_ADDED, Path: childPath, Right: rightValue[i]})
+					}
+				} else {
+					diffJsonValue(childPath, childKeys, leftValue[i], rightValue[i], ignored, differences)
+				}
+			}
+			return
+		}
+	case json.Number:
+		if rightValue, ok := right.(json.Number); ok && equalNumbers(leftValue, rightValue) {
+			return
+		}
+	default:
+		if left == right {
+			return
+		}
+	}
+	*differences = append(*differences, &Difference{Kind: DIFF_CHANGED, Path: path, Left: left, Right: right})
+}
+
+// isIgnoredPath tells if the keys start with one of the ignored paths, '*' matching any key or index.
+func isIgnoredPath(keys []string, ignored [][]string) bool {
+	for _, path := range ignored {
+		if len(path) > len(keys) {
+			continue
+		}
+		matches := true
+		for i := range path {
+			matches = matches && (path[i] == "*" || path[i] == keys[i])
+		}
+		if matches {
+			return true
+		}
+	}
+	return false
+}
+
+func diffValue(value interface{}) string {
+	buf := new(bytes.Buffer)
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return fmt.Sprint(value)
+	}
+	asString := strings.TrimSuffix(buf.String(), "\n")
+	if len(asString) > diffValueLength {
+		return asString[:diffValueLength] + "..."
+	}
+	return asString
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func differencesAsStrings(differences []*Difference) []string {
+	asStrings := make([]string, len(differences))
+	for i := range differences {
+		asStrings[i] = differences[i].String()
+	}
+	return asStrings
+}
+
+func TestDiffJson(t *testing.T) {
+	var left, right interface{}
+	json.Unmarshal([]byte(`{"id": 1, "name": "a", "tags": ["x", "y"], "owner": {"id": 7, "login": "b"}, "old": true}`), &left)
+	json.Unmarshal([]byte(`{"id": 2, "name": "a", "tags": ["x", "z", "w"], "owner": {"id": 8, "login": "c"}, "new": null}`), &right)
+
+	expected := []string{
+		`~ $.id: 1 -> 2`,
+		`+ $.new: null`,
+		`- $.old: true`,
+		`~ $.owner.id: 7 -> 8`,
+		`~ $.owner.login: "b" -> "c"`,
+		`~ $.tags[1]: "y" -> "z"`,
+		`+ $.tags[2]: "w"`,
+	}
+	if differences := differencesAsStrings(DiffJson(left, right, nil)); !reflect.DeepEqual(differences, expected) {
+		t.Errorf("Unexpected differences %v", differences)
+	}
+
+	expected = []string{`~ $.owner.login: "b" -> "c"`, `~ $.tags[1]: "y" -> "z"`}
+	if differences := differencesAsStrings(DiffJson(left, right, []string{"$.id", "*.id", "new", "old", "tags[2]"})); !reflect.DeepEqual(differences, expected) {
+		t.Errorf("Unexpected differences with ignored paths %v", differences)
+	}
+
+	if differences := DiffJson(left, right, []string{"$"}); len(differences) != 0 {
+		t.Errorf("Should have ignored the whole document but got %v", differencesAsStrings(differences))
+	}
+}
+
+func TestDiffJsonTypes(t *testing.T) {
+	var left, right interface{}
+	json.Unmarshal([]byte(`{"items": [{"id": 1}], "count": 1}`), &left)
+	json.Unmarshal([]byte(`{"items": {"id": 1}, "count": "1"}`), &right)
+
+	expected := []string{`~ $.count: 1 -> "1"`, `~ $.items: [{"id":1}] -> {"id":1}`}
+	if differences := differencesAsStrings(DiffJson(left, right, nil)); !reflect.DeepEqual(differences, expected) {
+		t.Errorf("Unexpected differences %v", differences)
+	}
+}
+
+func TestDiffResponses(t *testing.T) {
+	left := &Response{StatusCode: 200, Body: []byte(`{"items": [{"id": 1, "at": "10:00"}]}`)}
+	right := &Response{StatusCode: 200, Body: []byte(`{"items": [{"id": 1, "at": "11:00"}]}`)}
+	if differences := DiffResponses(left, right, []string{"items[*].at"}); len(differences) != 0 {
+		t.Errorf("Should not have differences but got %v", differencesAsStrings(differences))
+	}
+
+	right = &Response{StatusCode: 404, Body: []byte("line 1\nnot found\nline 3")}
+	left = &Response{StatusCode: 200, Body: []byte("line 1\nline 2\nline 3")}
+	expected := []string{`~ status: 200 -> 404`, `~ body line 2: "line 2" -> "not found"`}
+	if differences := differencesAsStrings(DiffResponses(left, right, nil)); !reflect.DeepEqual(differences, expected) {
+		t.Errorf("Unexpected differences %v", differences)
+	}
+}
+
+func TestRequestIgnore(t *testing.T) {
+	reader := &MockReader{configurations: make(map[string][]byte)}
+	reader.configurations["test"] = []byte(
+		`
+url: local
+
+endpoints:
+  test:
+    path: /test
+
+requests:
+  my_request:
+    endpoint: test
+    ignore:
+      - $.updated_at
+      - items[*].id
+`)
+
+	conf, err := NewConfiguration(reader)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if ignore := conf.Requests["my_request"].Ignore; !reflect.DeepEqual(ignore, []string{"$.updated_at", "items[*].id"}) {
+		t.Errorf("Unexpected ignore paths %v", ignore)
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Environment is overlaid onto the global configuration when selected with --env.
+type Environment struct {
+	Name      string
+	Url       string
+	Headers   []string
+	Options   []string
+	Variables map[string]interface{}
+}
+
+const ENVIRONMENTS = "environments"
+
+func readEnvironment(name string, value interface{}) (*Environment, error) {
+	environment := &Environment{Name: name, Variables: make(map[string]interface{})}
+	definition, ok := value.(map[interface{}]interface{})
+	if !ok {
+		if value == nil {
+			return environment, nil
+		}
+		return nil, errors.New(fmt.Sprintf("Environment '%v' must be a map", name))
+	}
+	for key, value := range definition {
+		switch key {
+		case URL:
+			environment.Url = fmt.Sprint(value)
+		case HEADERS, OPTIONS:
+			list, ok := value.([]interface{})
+			if !ok {
+				return nil, errors.New(fmt.Sprintf("Environment '%v' %v must be a list", name, key))
+			}
+			for i := range list {
+				if key == HEADERS {
+					environment.Headers = append(environment.Headers, fmt.Sprint(list[i]))
+				} else {
+					environment.Options = append(environment.Options, fmt.Sprint(list[i]))
+				}
+			}
+		case VARIABLES:
+			variables, ok := value.(map[interface{}]interface{})
+			if !ok {
+				return nil, errors.New(fmt.Sprintf("Environment '%v' variables must be a map", name))
+			}
+			for k, v := range variables {
+				environment.Variables[fmt.Sprint(k)] = v
+			}
+		default:
+			return nil, errors.New(fmt.Sprintf("Environment '%v' has an invalid attribute '%v'", name, key))
+		}
+	}
+	return environment, nil
+}
+
+// loadEnvironment overlays the selected environment: its url replaces the global one,
+// its variables override the global ones and its headers and options are merged into them.
+func (conf *Configuration) loadEnvironment() error {
+	if conf.Environment == "" {
+		return nil
+	}
+	environment := conf.Environments[conf.Environment]
+	if environment == nil {
+		return errors.New(fmt.Sprintf("Environment '%v' not found", conf.Environment))
+	}
+
+	if environment.Url != "" {
+		conf.GlobalUrl = environment.Url
+	}
+	for name, value := range environment.Variables {
+		conf.GlobalVariables[name] = value
+	}
+	conf.GlobalHeaders = mergeHeaders(conf.GlobalHeaders, environment.Headers)
+	conf.GlobalOptions = mergeOptions(conf.GlobalOptions, environment.Options)
+	return nil
+}
+
+func (conf *Configuration) EnvironmentNames() []string {
+	names := make([]string, 0, len(conf.Environments))
+	for name := range conf.Environments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
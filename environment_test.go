@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+var environmentsConfiguration = []byte(
+	`
+url: https://{env}.localhost
+
+variables:
+  env: dev
+  version: 1
+
+headers:
+  - 'Accept: application/json'
+
+environments:
+  staging:
+    variables:
+      env: staging
+  production:
+    url: https://api.localhost
+    variables:
+      version: 2
+    headers:
+      - 'Authorization: bearer prod'
+    options:
+      - '--compress'
+
+endpoints:
+  test:
+    path: /test
+
+requests:
+  my_request:
+    endpoint: test
+    version: '{version}'
+`)
+
+func TestNoEnvironmentSelected(t *testing.T) {
+	conf, err := NewConfiguration(&MockReader{configurations: map[string][]byte{"test": environmentsConfiguration}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if len(conf.Environments) != 2 {
+		t.Error("Should be 2 environments")
+	}
+	request := conf.Requests["my_request"]
+	if request.Url != "https://dev.localhost" || len(request.Headers) != 1 || len(request.Options) != 0 {
+		t.Errorf("Request configuration problem %v", request)
+	}
+}
+
+func TestEnvironmentVariables(t *testing.T) {
+	conf, err := NewEnvironmentConfiguration(&MockReader{configurations: map[string][]byte{"test": environmentsConfiguration}}, "staging")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if request := conf.Requests["my_request"]; request.Url != "https://staging.localhost" {
+		t.Errorf("Request configuration problem %v", request)
+	}
+}
+
+func TestEnvironmentOverlay(t *testing.T) {
+	conf, err := NewEnvironmentConfiguration(&MockReader{configurations: map[string][]byte{"test": environmentsConfiguration}}, "production")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	endpoint := conf.Endpoints["test"]
+	if endpoint.Url != "https://api.localhost" ||
+		!containsString(endpoint.Headers, "Accept: application/json") ||
+		!containsString(endpoint.Headers, "Authorization: bearer prod") ||
+		!containsString(endpoint.Options, "--compress") {
+		t.Errorf("Endpoint configuration problem %v", endpoint)
+	}
+	if conf.GlobalVariables["version"] != 2 {
+		t.Error("Environment variables should override the global variables")
+	}
+}
+
+func TestEnvironmentNotFound(t *testing.T) {
+	_, err := NewEnvironmentConfiguration(&MockReader{configurations: map[string][]byte{"test": environmentsConfiguration}}, "uat")
+
+	if err == nil || err.Error() != "Environment 'uat' not found" {
+		t.Error("Should have thrown an environment not found error but got ", err)
+	}
+}
+
+func TestInvalidEnvironmentAttribute(t *testing.T) {
+	reader := &MockReader{configurations: make(map[string][]byte)}
+	reader.configurations["test"] = []byte(
+		`
+url: local
+
+environments:
+  staging:
+    path: /staging
+
+endpoints:
+  test:
+    path: /test
+`)
+
+	if _, err := NewConfiguration(reader); err == nil || err.Error() != "Environment 'staging' has an invalid attribute 'path'" {
+		t.Error("Should have thrown an invalid attribute error but got ", err)
+	}
+}
+
+func TestShowEnvironments(t *testing.T) {
+	conf, _ := NewEnvironmentConfiguration(&MockReader{configurations: map[string][]byte{"test": environmentsConfiguration}}, "staging")
+
+	var b bytes.Buffer
+	printer := &Printer{conf: conf, writer: &b}
+	printer.ShowEnvironments()
+
+	if showEnvironmentsOutput != strings.Trim(b.String(), " \n\t") {
+		t.Errorf("Environments output doesn't look correct %v", b.String())
+	}
+}
+
+var showEnvironmentsOutput = `Environment production:
+        url: https://api.localhost
+        version: 2
+        -H 'Authorization: bearer prod'
+        --compress
+
+Environment staging (selected):
+        env: staging`
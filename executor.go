@@ -5,21 +5,49 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/textproto"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 	"text/template"
+	"time"
 )
 
+// variablePattern matches the {variable} placeholders, leaving alone the braces of json bodies.
+var variablePattern = regexp.MustCompile(`{[\w.\-]+}`)
+
 type Executor struct {
 	conf      *Configuration
 	runner    CommandRunner
 	varReader VariableReader
+	output    *ResponsePrinter
+	history   *HistoryStore
+	// only one request at a time asks for its variables
+	readMutex sync.Mutex
 }
 
+// CommandRunner executes a fully resolved request. The command is the request
+// rendered as curl arguments, runners not using curl can interpret the request instead.
+// Runners must be safe for concurrent use, 'run --parallel' executes several requests at once.
 type CommandRunner interface {
-	Run(command []string) error
+	Run(request *Request, command []string) (*Response, error)
+}
+
+type Response struct {
+	// Request is the resolved request that got this response
+	Request    *Request
+	Status     string
+	StatusCode int
+	Headers    http.Header
+	Body       []byte
+	Stderr     []byte
+	Elapsed    time.Duration
 }
 
 type DefaultRunner struct {
@@ -32,8 +60,23 @@ type VariableReader interface {
 type DefaultVariableReader struct {
 }
 
+const (
+	CURL_RUNNER   = "curl"
+	HTTP_RUNNER   = "http"
+	REPLAY_RUNNER = "replay"
+)
+
+var runners = map[string]func(conf *Configuration) CommandRunner{
+	"":          func(conf *Configuration) CommandRunner { return &DefaultRunner{} },
+	CURL_RUNNER: func(conf *Configuration) CommandRunner { return &DefaultRunner{} },
+	HTTP_RUNNER: func(conf *Configuration) CommandRunner { return &HttpRunner{} },
+	REPLAY_RUNNER: func(conf *Configuration) CommandRunner {
+		return &ReplayRunner{file: filepath.Join(conf.reader.Directory(), RECORDINGS_FILE)}
+	},
+}
+
 func NewDefaultExecutor(conf *Configuration) *Executor {
-	runner := &DefaultRunner{}
+	runner := runners[conf.Runner](conf)
 	varReader := &DefaultVariableReader{}
 	return NewExecutor(conf, runner, varReader)
 }
@@ -43,55 +86,265 @@ func NewExecutor(conf *Configuration, runner CommandRunner, varReader VariableRe
 		conf:      conf,
 		runner:    runner,
 		varReader: varReader,
+		output:    &ResponsePrinter{writer: os.Stdout, output: OUTPUT_RAW},
 	}
 	return executor
 }
 
+// SetOutput changes how RunRequest prints the responses.
+func (executor *Executor) SetOutput(output *ResponsePrinter) {
+	executor.output = output
+}
+
+// SetHistory keeps every execution in the history store.
+func (executor *Executor) SetHistory(history *HistoryStore) {
+	executor.history = history
+}
+
 func (executor *Executor) RunRequest(requestName string, args []string) error {
+	response, err := executor.ExecuteRequest(requestName, args)
+	executor.output.Print(requestName, response, err)
+	return err
+}
+
+// ExecuteRequest runs a request or endpoint. A response can be returned together with
+// an error when the request ran but its captures failed.
+func (executor *Executor) ExecuteRequest(requestName string, args []string) (*Response, error) {
+	return executor.ExecuteRequestWithVariables(requestName, nil, args)
+}
+
+// ExecuteRequestWithVariables runs a request or endpoint as if the variables were set on the request.
+func (executor *Executor) ExecuteRequestWithVariables(requestName string, variables map[interface{}]interface{}, args []string) (*Response, error) {
+	request, values, err := executor.createExecutable(requestName, variables, args)
+	if err != nil {
+		return nil, err
+	}
+	return executor.runExecutable(request, values)
+}
+
+// ResolveRequest returns a request or endpoint ready to run, without running it.
+func (executor *Executor) ResolveRequest(requestName string, args []string) (*Request, error) {
+	request, values, err := executor.createExecutable(requestName, nil, args)
+	if err != nil {
+		return nil, err
+	}
+	return executor.resolveVariables(request, values)
+}
+
+// createExecutable creates a request or endpoint and binds the args to the variables it misses
+// without the session, in the order they appear. So the args always go to the same variables
+// and take precedence over the captured values.
+func (executor *Executor) createExecutable(requestName string, variables map[interface{}]interface{}, args []string) (*Request, map[string]string, error) {
+	values := make(map[string]string)
+	session := executor.conf.Session
+	if len(args) > 0 {
+		request, err := executor.newExecutable(requestName, variables, NewVariableStore())
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, name := range unresolvedVariables(request) {
+			if len(values) == len(args) {
+				break
+			}
+			values[name] = args[len(values)]
+		}
+		session = session.Without(values)
+	}
+	request, err := executor.newExecutable(requestName, variables, session)
+	return request, values, err
+}
+
+func (executor *Executor) newExecutable(requestName string, variables map[interface{}]interface{}, session *VariableStore) (*Request, error) {
 	request := executor.conf.Requests[requestName]
 	if request != nil {
-		return executor.runExecutable(request, args)
+		// created again so variables captured since loading the configuration are used
+		return executor.conf.newSessionRequest(requestName, withVariables(request.Parameters, variables), session)
 	}
 
 	endpoint := executor.conf.Endpoints[requestName]
 	if endpoint != nil {
-		if r, err := executor.createTemporaryRequest(requestName); err == nil {
-			return executor.runExecutable(r, args)
-		} else {
-			return err
+		m := make(map[interface{}]interface{}, 1)
+		m["endpoint"] = requestName
+		return executor.conf.newSessionRequest(requestName, withVariables(m, variables), session)
+	}
+	return nil, errors.New(fmt.Sprint("Could not find request/endpoint ", requestName))
+}
+
+// unresolvedVariables returns the names of the variables of a request, in the order they appear.
+func unresolvedVariables(request *Request) []string {
+	requestAsString := renderRunCommand(request)
+	if query := request.UrlQuery(); query != "" && request.QueryRaw == "" {
+		// with a body the query list is escaped in the url, where its placeholders wouldn't be found
+		requestAsString = strings.Replace(requestAsString, query, "?"+rawQueryList(request.QueryListKeys, request.QueryList), 1)
+	}
+	requestAsString = environmentVariablePattern.ReplaceAllString(requestAsString, "")
+	var names []string
+	seen := make(map[string]bool)
+	for _, v := range variablePattern.FindAllString(requestAsString, -1) {
+		name := strings.Trim(v, "{}")
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
 		}
 	}
-	return errors.New(fmt.Sprint("Could not find request/endpoint ", requestName))
+	return names
 }
 
-func (executor *Executor) createTemporaryRequest(requestName string) (*Request, error) {
-	m := make(map[interface{}]interface{}, 1)
-	m["endpoint"] = requestName
-	return executor.conf.createRequest(requestName, m)
+func withVariables(parameters map[interface{}]interface{}, variables map[interface{}]interface{}) map[interface{}]interface{} {
+	if len(variables) == 0 {
+		return parameters
+	}
+	merged := make(map[interface{}]interface{}, len(parameters)+len(variables))
+	for k, v := range parameters {
+		merged[k] = v
+	}
+	for k, v := range variables {
+		merged[k] = v
+	}
+	return merged
 }
 
-func (executor *Executor) runExecutable(executable Executable, args []string) error {
-	t := template.Must(template.New("curlTemplate").Parse(runCurlTemplate))
-	buf := new(bytes.Buffer)
-	t.Execute(buf, executable)
-
-	requestAsString := buf.String()
-	if !executor.hasResolvedAllVariables(requestAsString) {
-		re := regexp.MustCompile("{(.+?)}")
-		for i, v := range re.FindAllString(requestAsString, -1) {
-			value := executor.getValue(v, i, args)
-			requestAsString = strings.Replace(requestAsString, v, value, -1)
+func (executor *Executor) runExecutable(request *Request, values map[string]string) (*Response, error) {
+	resolved, err := executor.resolveVariables(request, values)
+	if err != nil {
+		return nil, err
+	}
+	response, err := executor.execute(resolved)
+	if err != nil {
+		return response, err
+	}
+	return response, executor.capture(request, response)
+}
+
+// Rerun runs again the resolved request of a history entry, without asking for variables.
+// The environment variables are resolved again and the redacted headers are taken from the
+// request as it is configured now.
+func (executor *Executor) Rerun(entry *HistoryEntry) (*Response, error) {
+	request := entry.AsRequest()
+	request.secrets = executor.conf.environmentValues(request)
+	if unresolved := executor.conf.replaceEnvironmentVariables(request); len(unresolved) > 0 {
+		return nil, errors.New("Unresolved environment variables: " + strings.Join(unresolved, ", "))
+	}
+	for i, header := range request.Headers {
+		if !isRedactedHeader(header) {
+			continue
 		}
+		configured, ok := executor.configuredHeader(entry.Name, headerName(header))
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("Could not rerun %v, its %v header isn't kept in the history, run it with 'gohit run'",
+				entry.Name, header[:strings.Index(header, ":")]))
+		}
+		request.Headers[i] = configured
 	}
+	for i, option := range request.Options {
+		if !strings.Contains(option, HISTORY_REDACTED) {
+			continue
+		}
+		configured, ok := executor.configuredOption(entry.Name, option)
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("Could not rerun %v, its '%v' option isn't kept in the history, run it with 'gohit run'", entry.Name, option))
+		}
+		request.Options[i] = configured
+	}
+	return executor.execute(request)
+}
 
-	asArray := strings.Split(requestAsString, "\n")
-	return executor.runner.Run(asArray)
+// configuredOption returns the option of a request or endpoint that was redacted, when it doesn't need any variable.
+func (executor *Executor) configuredOption(requestName string, redacted string) (string, bool) {
+	request, err := executor.newExecutable(requestName, nil, executor.conf.Session)
+	if err != nil {
+		return "", false
+	}
+	executor.conf.replaceEnvironmentVariables(request)
+	for i, option := range redactedOptions(request.Options) {
+		if option == redacted && !variablePattern.MatchString(request.Options[i]) {
+			return request.Options[i], true
+		}
+	}
+	return "", false
+}
+
+// configuredHeader returns a header of a request or endpoint, when it doesn't need any variable.
+func (executor *Executor) configuredHeader(requestName string, name string) (string, bool) {
+	request, err := executor.newExecutable(requestName, nil, executor.conf.Session)
+	if err != nil {
+		return "", false
+	}
+	executor.conf.replaceEnvironmentVariables(request)
+	for _, header := range request.Headers {
+		if headerName(header) == name && !variablePattern.MatchString(header) && !environmentVariablePattern.MatchString(header) {
+			return header, true
+		}
+	}
+	return "", false
+}
+
+// execute runs a resolved request and adds it to the history.
+func (executor *Executor) execute(resolved *Request) (*Response, error) {
+	response, err := executor.runner.Run(resolved, commandAsArray(resolved))
+	if err != nil {
+		return nil, err
+	}
+	response.Request = resolved
+	if executor.history != nil {
+		if _, err := executor.history.Add(resolved.Name, executor.conf.Environment, response); err != nil {
+			return response, errors.New(fmt.Sprint("Could not save the history: ", err))
+		}
+	}
+	return response, nil
+}
+
+// capture stores the values captured from the response into the session variables.
+func (executor *Executor) capture(request *Request, response *Response) error {
+	for name, capture := range request.Capture {
+		value, err := capture.Extract(response)
+		if err != nil {
+			return errors.New(fmt.Sprintf("Request %v couldn't capture %v: %v", request.Name, name, err))
+		}
+		executor.conf.Session.Set(name, value)
+	}
+	return nil
 }
 
-func (executor *Executor) getValue(variableName string, position int, args []string) string {
-	if len(args) > position {
-		return args[position]
+// resolveVariables returns a copy of the request with the variables still missing
+// taken from the values bound to the args or the session, or else read from the user.
+// Missing environment variables are an error instead.
+func (executor *Executor) resolveVariables(request *Request, values map[string]string) (*Request, error) {
+	resolved := request.copy()
+	resolved.secrets = executor.conf.environmentValues(resolved)
+	if unresolved := executor.conf.replaceEnvironmentVariables(resolved); len(unresolved) > 0 {
+		return nil, errors.New("Unresolved environment variables: " + strings.Join(unresolved, ", "))
 	}
+	for _, name := range unresolvedVariables(resolved) {
+		value, ok := values[name]
+		if !ok {
+			if value, ok = executor.conf.Session.Get(name); !ok {
+				value = executor.readValue("{" + name + "}")
+			}
+		}
+		executor.conf.replaceAll(resolved, "{"+name+"}", value)
+	}
+	return resolved, nil
+}
+
+func renderRunCommand(request *Request) string {
+	t := template.Must(template.New("curlTemplate").Parse(runCurlTemplate))
+	buf := new(bytes.Buffer)
+	t.Execute(buf, request)
+	return buf.String()
+}
+
+func commandAsArray(request *Request) []string {
+	asArray := strings.Split(renderRunCommand(request), "\n")
+	for i := range asArray {
+		asArray[i] = strings.Replace(asArray[i], tokenNewline, "\n", -1)
+	}
+	return asArray
+}
+
+func (executor *Executor) readValue(variableName string) string {
+	executor.readMutex.Lock()
+	defer executor.readMutex.Unlock()
 	return executor.varReader.Read(variableName)
 }
 
@@ -102,23 +355,54 @@ func (parameterReader *DefaultVariableReader) Read(variableName string) string {
 	return strings.TrimSpace(value)
 }
 
-func (runner *DefaultRunner) Run(command []string) error {
-	cmd := exec.Command("curl", command...)
+// Run executes curl, dumping the response headers to a temporary file so the
+// status and headers can be returned along with the body.
+func (runner *DefaultRunner) Run(request *Request, command []string) (*Response, error) {
+	headersFile, err := ioutil.TempFile("", "gohit-headers")
+	if err != nil {
+		return nil, err
+	}
+	headersFile.Close()
+	defer os.Remove(headersFile.Name())
+
+	cmd := exec.Command("curl", append(command, "--dump-header", headersFile.Name())...)
 	var out bytes.Buffer
 	var stderr bytes.Buffer
 	cmd.Stdout = &out
 	cmd.Stderr = &stderr
+	start := time.Now()
 	if err := cmd.Run(); err != nil {
-		return err
+		return nil, err
 	}
-	fmt.Println(out.String())
-	if stderr.Len() > 0 {
-		fmt.Println("#### Stderr ####")
-		fmt.Println(stderr.String())
+	response := &Response{
+		Body:    out.Bytes(),
+		Stderr:  stderr.Bytes(),
+		Elapsed: time.Since(start),
+		Headers: make(http.Header),
 	}
-	return nil
+	if headers, err := ioutil.ReadFile(headersFile.Name()); err == nil {
+		readDumpedHeaders(response, headers)
+	}
+	return response, nil
 }
 
-func (executor *Executor) hasResolvedAllVariables(request string) bool {
-	return strings.Index(request, "{") == -1
+// readDumpedHeaders parses the output of curl's --dump-header. When redirects are
+// followed there is one block per response, only the last one is kept.
+func readDumpedHeaders(response *Response, headers []byte) {
+	blocks := strings.Split(strings.TrimSpace(strings.Replace(string(headers), "\r\n", "\n", -1)), "\n\n")
+	lines := strings.Split(blocks[len(blocks)-1], "\n")
+
+	statusLine := strings.SplitN(lines[0], " ", 3)
+	if len(statusLine) < 2 {
+		return
+	}
+	response.StatusCode, _ = strconv.Atoi(statusLine[1])
+	response.Status = strings.Join(statusLine[1:], " ")
+
+	for _, line := range lines[1:] {
+		if i := strings.Index(line, ":"); i > 0 {
+			name := textproto.CanonicalMIMEHeaderKey(strings.TrimSpace(line[:i]))
+			response.Headers.Add(name, strings.TrimSpace(line[i+1:]))
+		}
+	}
 }
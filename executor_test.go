@@ -2,6 +2,10 @@ package main
 
 import (
 	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
 	"reflect"
 	"testing"
 )
@@ -174,11 +178,155 @@ requests:
 	}
 }
 
-func (runner *MockCommandRunner) Run(command []string) error {
+func TestExecuteRequestWithBody(t *testing.T) {
+	conf, err := NewConfiguration(NewSilentConfigurationReader("_resources/valid", "api-body.yaml"))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	command := []string{"https://localhost/inline", "--data-binary", "id=argId&name=request", "-XPOST"}
+	executor := NewExecutor(conf, &MockCommandRunner{command: command}, &MockVariableReader{})
+
+	if err := executor.RunRequest("create_override", []string{"argId"}); err != nil {
+		t.Error("Should not throw an error ", err)
+	}
+}
+
+func TestExecuteEndpointWithJsonBody(t *testing.T) {
+	conf, err := NewConfiguration(NewSilentConfigurationReader("_resources/valid", "api-body.yaml"))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	command := []string{"https://localhost/structured", "-H", "Content-type: application/json",
+		"--data-binary", `{"name":"gohit","tags":["curl","yaml"]}`, "-XPOST"}
+	executor := NewExecutor(conf, &MockCommandRunner{command: command}, &MockVariableReader{})
+
+	if err := executor.RunRequest("create_structured", nil); err != nil {
+		t.Error("Should not throw an error ", err)
+	}
+
+	command = []string{"https://localhost/file", "--data-binary", "{\n  \"name\": \"gohit\"\n}\n", "-XPOST"}
+	executor = NewExecutor(conf, &MockCommandRunner{command: command}, &MockVariableReader{})
+
+	if err := executor.RunRequest("create_file", nil); err != nil {
+		t.Error("Should not throw an error ", err)
+	}
+}
+
+func TestExecuteWithQueryListAndBody(t *testing.T) {
+	if _, err := exec.LookPath("curl"); err != nil {
+		t.Skip("curl is not installed")
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Write([]byte(r.Method + " " + r.URL.RequestURI() + " " + string(body)))
+	}))
+	defer server.Close()
+
+	reader := &MockReader{configurations: make(map[string][]byte)}
+	reader.configurations["test"] = []byte(`
+url: ` + server.URL + `
+
+endpoints:
+  create_item:
+    method: POST
+    path: /items
+    query:
+      - page: '1'
+      - q: 'a b'
+    body:
+      name: x
+  list_items:
+    path: /items
+    query:
+      - q: 'a b'
+  create_page:
+    method: POST
+    path: /items
+    query:
+      - page: '{page}'
+      - q: 'a b'
+    body: '{name}'
+`)
+	conf, err := NewConfiguration(reader)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	command := []string{server.URL + "/items?page=1&q=a+b", "--data-binary", `{"name":"x"}`, "-XPOST"}
+	executor := NewExecutor(conf, &MockCommandRunner{command: command}, &MockVariableReader{})
+	if err := executor.RunRequest("create_item", nil); err != nil {
+		t.Error("Should not throw an error ", err)
+	}
+
+	executor = NewExecutor(conf, &DefaultRunner{}, &MockVariableReader{})
+	for name, expected := range map[string]string{
+		"create_item": `POST /items?page=1&q=a+b {"name":"x"}`,
+		"list_items":  "GET /items?q=a+b ",
+	} {
+		response, err := executor.ExecuteRequest(name, nil)
+		if err != nil {
+			t.Error("Should not throw an error ", err)
+		} else if string(response.Body) != expected {
+			t.Errorf("Unexpected curl request for %v: %v", name, string(response.Body))
+		}
+	}
+
+	for _, runner := range []CommandRunner{&DefaultRunner{}, &HttpRunner{}} {
+		executor = NewExecutor(conf, runner, &MockVariableReader{})
+		response, err := executor.ExecuteRequest("create_page", []string{"2", "y z"})
+		if err != nil {
+			t.Error("Should not throw an error ", err)
+		} else if string(response.Body) != "POST /items?page=2&q=a+b y z" {
+			t.Errorf("Should have bound the args to the query and body placeholders but got %v", string(response.Body))
+		}
+	}
+}
+
+func TestExecuteRequestWithUnresolvedEnvironmentVariables(t *testing.T) {
+	reader := &MockReader{configurations: make(map[string][]byte)}
+	reader.configurations["test"] = []byte(
+		`
+url: local
+
+endpoints:
+  test:
+    path: /test/{env:GOHIT_TEST_MISSING}
+    headers:
+      - 'Authorization: bearer ${GOHIT_TEST_TOKEN}'
+`)
+
+	conf, err := NewConfiguration(reader)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	executor := NewExecutor(conf, &MockCommandRunner{}, &MockVariableReader{})
+
+	if err := executor.RunRequest("test", nil); err == nil ||
+		err.Error() != "Unresolved environment variables: GOHIT_TEST_MISSING, GOHIT_TEST_TOKEN" {
+		t.Error("Should have thrown an unresolved environment variables error but got ", err)
+	}
+}
+
+func TestReadDumpedHeaders(t *testing.T) {
+	response := &Response{Headers: make(map[string][]string)}
+	readDumpedHeaders(response, []byte("HTTP/1.1 301 Moved Permanently\r\nLocation: /new\r\n\r\n"+
+		"HTTP/2 200\r\ncontent-type: application/json\r\n\r\n"))
+
+	if response.StatusCode != 200 || response.Status != "200" ||
+		response.Headers.Get("Content-Type") != "application/json" || response.Headers.Get("Location") != "" {
+		t.Errorf("Should have read the last headers block but got %v %v", response.Status, response.Headers)
+	}
+}
+
+func (runner *MockCommandRunner) Run(request *Request, command []string) (*Response, error) {
 	if !reflect.DeepEqual(command, runner.command) {
-		return errors.New("CommandRunner array is not correct")
+		return nil, errors.New("CommandRunner array is not correct")
 	}
-	return nil
+	return &Response{StatusCode: 200, Status: "200 OK", Body: []byte("{}")}, nil
 }
 
 func (parameterReader *MockVariableReader) Read(variableName string) string {
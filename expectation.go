@@ -0,0 +1,187 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Expectation is what a response must look like for a request to pass 'gohit test'.
+type Expectation struct {
+	Status     int
+	Headers    map[string]string
+	Json       map[string]interface{}
+	JsonMatch  map[string]string
+	Contains   []string
+	MaxLatency time.Duration
+}
+
+const (
+	EXPECT_STATUS      = "status"
+	EXPECT_HEADERS     = "headers"
+	EXPECT_JSON        = "json"
+	EXPECT_JSON_MATCH  = "json_match"
+	EXPECT_CONTAINS    = "contains"
+	EXPECT_MAX_LATENCY = "max_latency"
+)
+
+func readExpectation(value interface{}) (*Expectation, error) {
+	definition, ok := value.(map[interface{}]interface{})
+	if !ok {
+		return nil, errors.New("expect must be a map")
+	}
+	expectation := &Expectation{
+		Headers:   make(map[string]string),
+		Json:      make(map[string]interface{}),
+		JsonMatch: make(map[string]string),
+	}
+	for key, value := range definition {
+		switch key {
+		case EXPECT_STATUS:
+			status, ok := value.(int)
+			if !ok {
+				return nil, errors.New(fmt.Sprintf("Invalid status '%v'", value))
+			}
+			expectation.Status = status
+		case EXPECT_HEADERS:
+			headers, ok := value.(map[interface{}]interface{})
+			if !ok {
+				return nil, errors.New("headers must be a map")
+			}
+			for name, pattern := range headers {
+				if _, err := regexp.Compile(fmt.Sprint(pattern)); err != nil {
+					return nil, err
+				}
+				expectation.Headers[fmt.Sprint(name)] = fmt.Sprint(pattern)
+			}
+		case EXPECT_JSON:
+			paths, ok := value.(map[interface{}]interface{})
+			if !ok {
+				return nil, errors.New("json must be a map")
+			}
+			for path, expected := range paths {
+				expectation.Json[fmt.Sprint(path)] = toJsonValue(expected)
+			}
+		case EXPECT_JSON_MATCH:
+			paths, ok := value.(map[interface{}]interface{})
+			if !ok {
+				return nil, errors.New("json_match must be a map")
+			}
+			for path, pattern := range paths {
+				if _, err := regexp.Compile(fmt.Sprint(pattern)); err != nil {
+					return nil, err
+				}
+				expectation.JsonMatch[fmt.Sprint(path)] = fmt.Sprint(pattern)
+			}
+		case EXPECT_CONTAINS:
+			if contains, ok := value.([]interface{}); ok {
+				for i := range contains {
+					expectation.Contains = append(expectation.Contains, fmt.Sprint(contains[i]))
+				}
+			} else {
+				expectation.Contains = []string{fmt.Sprint(value)}
+			}
+		case EXPECT_MAX_LATENCY:
+			latency, err := readDuration(value)
+			if err != nil {
+				return nil, err
+			}
+			expectation.MaxLatency = latency
+		default:
+			return nil, errors.New(fmt.Sprintf("Invalid expect attribute '%v'", key))
+		}
+	}
+	return expectation, nil
+}
+
+// readDuration accepts a Go duration like '500ms' or a number of milliseconds.
+func readDuration(value interface{}) (time.Duration, error) {
+	switch duration := value.(type) {
+	case int:
+		return time.Duration(duration) * time.Millisecond, nil
+	case string:
+		return time.ParseDuration(duration)
+	}
+	return 0, errors.New(fmt.Sprintf("Invalid duration '%v'", value))
+}
+
+// Check returns one message per expectation the response fails, sorted to keep the output stable.
+func (expectation *Expectation) Check(response *Response) []string {
+	var failures []string
+	if expectation.Status != 0 && response.StatusCode != expectation.Status {
+		failures = append(failures, fmt.Sprintf("status %v, expected %v", response.StatusCode, expectation.Status))
+	}
+
+	for name, pattern := range expectation.Headers {
+		values, ok := response.Headers[http.CanonicalHeaderKey(name)]
+		if !ok {
+			failures = append(failures, fmt.Sprintf("header %v missing", name))
+		} else if value := strings.Join(values, ", "); !regexp.MustCompile(pattern).MatchString(value) {
+			failures = append(failures, fmt.Sprintf("header %v '%v' doesn't match '%v'", name, value, pattern))
+		}
+	}
+
+	if len(expectation.Json) > 0 || len(expectation.JsonMatch) > 0 {
+		failures = append(failures, expectation.checkJson(response.Body)...)
+	}
+
+	for _, contains := range expectation.Contains {
+		if !strings.Contains(string(response.Body), contains) {
+			failures = append(failures, fmt.Sprintf("body doesn't contain '%v'", contains))
+		}
+	}
+
+	if expectation.MaxLatency != 0 && response.Elapsed > expectation.MaxLatency {
+		failures = append(failures, fmt.Sprintf("latency %v above %v", response.Elapsed, expectation.MaxLatency))
+	}
+
+	sort.Strings(failures)
+	return failures
+}
+
+func (expectation *Expectation) checkJson(body []byte) []string {
+	var document interface{}
+	if err := json.Unmarshal(body, &document); err != nil {
+		return []string{"body is not json: " + err.Error()}
+	}
+
+	var failures []string
+	for path, expected := range expectation.Json {
+		value, found := jsonPath(document, path)
+		if !found {
+			failures = append(failures, fmt.Sprintf("json %v missing", path))
+			continue
+		}
+		expectedAsJson, _ := json.Marshal(expected)
+		valueAsJson, _ := json.Marshal(value)
+		if string(expectedAsJson) != string(valueAsJson) {
+			failures = append(failures, fmt.Sprintf("json %v is %s, expected %s", path, valueAsJson, expectedAsJson))
+		}
+	}
+
+	for path, pattern := range expectation.JsonMatch {
+		value, found := jsonPath(document, path)
+		if !found {
+			failures = append(failures, fmt.Sprintf("json %v missing", path))
+			continue
+		}
+		if asString := jsonValueAsString(value); !regexp.MustCompile(pattern).MatchString(asString) {
+			failures = append(failures, fmt.Sprintf("json %v '%v' doesn't match '%v'", path, asString, pattern))
+		}
+	}
+	return failures
+}
+
+// jsonValueAsString returns strings as they are and anything else as json.
+func jsonValueAsString(value interface{}) string {
+	if asString, ok := value.(string); ok {
+		return asString
+	}
+	asJson, _ := json.Marshal(value)
+	return string(asJson)
+}
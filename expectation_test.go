@@ -0,0 +1,126 @@
+package main
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLoadExpectation(t *testing.T) {
+	reader := &MockReader{configurations: make(map[string][]byte)}
+	reader.configurations["test"] = []byte(
+		`
+url: local
+
+endpoints:
+  test:
+    path: /test
+
+requests:
+  my_request:
+    endpoint: test
+    expect:
+      status: 200
+      headers:
+        Content-Type: json
+      json:
+        id: 1
+        owner.login: fabiofalci
+      json_match:
+        name: ^go
+      contains: gohit
+      max_latency: 1s
+`)
+
+	conf, err := NewConfiguration(reader)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	expect := conf.Requests["my_request"].Expect
+	if expect == nil ||
+		expect.Status != 200 ||
+		expect.Headers["Content-Type"] != "json" ||
+		len(expect.Json) != 2 ||
+		expect.JsonMatch["name"] != "^go" ||
+		!reflect.DeepEqual(expect.Contains, []string{"gohit"}) ||
+		expect.MaxLatency != time.Second {
+		t.Errorf("Expectation configuration problem %v", expect)
+	}
+}
+
+func TestInvalidExpectation(t *testing.T) {
+	reader := &MockReader{configurations: make(map[string][]byte)}
+	reader.configurations["test"] = []byte(
+		`
+url: local
+
+endpoints:
+  test:
+    path: /test
+
+requests:
+  my_request:
+    endpoint: test
+    expect:
+      code: 200
+`)
+
+	if _, err := NewConfiguration(reader); err == nil || err.Error() != "Request my_request has an invalid expect: Invalid expect attribute 'code'" {
+		t.Error("Should have thrown an invalid expect error but got ", err)
+	}
+}
+
+func TestExpectationPasses(t *testing.T) {
+	expectation := &Expectation{
+		Status:     200,
+		Headers:    map[string]string{"content-type": "^application/json"},
+		Json:       map[string]interface{}{"id": 1, "owner.login": "fabiofalci"},
+		JsonMatch:  map[string]string{"name": "^go"},
+		Contains:   []string{"gohit"},
+		MaxLatency: time.Second,
+	}
+	response := &Response{
+		StatusCode: 200,
+		Headers:    http.Header{"Content-Type": []string{"application/json"}},
+		Body:       []byte(`{"id": 1, "name": "gohit", "owner": {"login": "fabiofalci"}}`),
+		Elapsed:    time.Millisecond,
+	}
+
+	if failures := expectation.Check(response); len(failures) != 0 {
+		t.Errorf("Should have passed but got %v", failures)
+	}
+}
+
+func TestExpectationFails(t *testing.T) {
+	expectation := &Expectation{
+		Status:     200,
+		Headers:    map[string]string{"Content-Type": "^application/json", "Location": "."},
+		Json:       map[string]interface{}{"id": 2, "missing": true},
+		JsonMatch:  map[string]string{"name": "^curl"},
+		Contains:   []string{"sconsify"},
+		MaxLatency: time.Millisecond,
+	}
+	response := &Response{
+		StatusCode: 404,
+		Headers:    http.Header{"Content-Type": []string{"text/html"}},
+		Body:       []byte(`{"id": 1, "name": "gohit"}`),
+		Elapsed:    time.Second,
+	}
+
+	expected := []string{
+		"body doesn't contain 'sconsify'",
+		"header Content-Type 'text/html' doesn't match '^application/json'",
+		"header Location missing",
+		"json id is 1, expected 2",
+		"json missing missing",
+		"json name 'gohit' doesn't match '^curl'",
+		"latency 1s above 1ms",
+		"status 404, expected 200",
+	}
+	if failures := expectation.Check(response); !reflect.DeepEqual(failures, expected) {
+		t.Errorf("Should have failed with %v but got %v", expected, failures)
+	}
+}
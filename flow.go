@@ -0,0 +1,116 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+// Flow is an ordered list of requests, e.g. create, fetch, update and delete a resource.
+type Flow struct {
+	Name  string
+	Steps []*FlowStep
+}
+
+// FlowStep runs a request or endpoint, overriding some of its variables.
+type FlowStep struct {
+	Request   string
+	Variables map[interface{}]interface{}
+}
+
+const (
+	FLOWS   = "flows"
+	REQUEST = "request"
+)
+
+func readFlow(name string, value interface{}) (*Flow, error) {
+	steps, ok := value.([]interface{})
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("Flow '%v' must be a list of steps", name))
+	}
+	flow := &Flow{Name: name}
+	for i := range steps {
+		step := &FlowStep{Variables: make(map[interface{}]interface{})}
+		switch definition := steps[i].(type) {
+		case string:
+			step.Request = definition
+		case map[interface{}]interface{}:
+			for key, value := range definition {
+				switch key {
+				case REQUEST:
+					step.Request = fmt.Sprint(value)
+				case VARIABLES:
+					variables, ok := value.(map[interface{}]interface{})
+					if !ok {
+						return nil, errors.New(fmt.Sprintf("Flow '%v' step %v variables must be a map", name, i+1))
+					}
+					step.Variables = variables
+				default:
+					return nil, errors.New(fmt.Sprintf("Flow '%v' step %v has an invalid attribute '%v'", name, i+1, key))
+				}
+			}
+		}
+		if step.Request == "" {
+			return nil, errors.New(fmt.Sprintf("Flow '%v' step %v missing request", name, i+1))
+		}
+		flow.Steps = append(flow.Steps, step)
+	}
+	return flow, nil
+}
+
+// FlowRunner executes the steps of a flow in order, stopping at the first failure.
+type FlowRunner struct {
+	executor *Executor
+	writer   io.Writer
+}
+
+func NewFlowRunner(executor *Executor, writer io.Writer) *FlowRunner {
+	return &FlowRunner{executor: executor, writer: writer}
+}
+
+// Run returns an error when the flow doesn't exist or one of its steps fails.
+func (runner *FlowRunner) Run(flowName string) error {
+	flow := runner.executor.conf.Flows[flowName]
+	if flow == nil {
+		return errors.New(fmt.Sprint("Could not find flow ", flowName))
+	}
+
+	summary := tabwriter.NewWriter(runner.writer, 0, 0, 2, ' ', 0)
+	for i, step := range flow.Steps {
+		response, err := runner.executor.ExecuteRequestWithVariables(step.Request, step.Variables, nil)
+		if err == nil {
+			err = runner.check(step, response)
+		}
+		if err != nil {
+			status := ""
+			if response != nil {
+				status = response.Status
+			}
+			fmt.Fprintf(summary, "%v.\t%v\t%v\tFAIL\t%v\n", i+1, step.Request, status, err)
+			summary.Flush()
+			fmt.Fprintf(runner.writer, "\nFlow %v stopped at step %v of %v\n", flowName, i+1, len(flow.Steps))
+			return errors.New(fmt.Sprintf("Flow %v failed", flowName))
+		}
+		fmt.Fprintf(summary, "%v.\t%v\t%v\tOK\t%v\n", i+1, step.Request, response.Status, response.Elapsed.Round(time.Millisecond))
+	}
+	summary.Flush()
+	fmt.Fprintf(runner.writer, "\nFlow %v finished, %v steps\n", flowName, len(flow.Steps))
+	return nil
+}
+
+// check fails a step on an http error status or when its request expectations fail.
+func (runner *FlowRunner) check(step *FlowStep, response *Response) error {
+	if response.StatusCode >= 400 {
+		return errors.New(fmt.Sprintf("status %v", response.StatusCode))
+	}
+	request := runner.executor.conf.Requests[step.Request]
+	if request == nil || request.Expect == nil {
+		return nil
+	}
+	if failures := request.Expect.Check(response); len(failures) > 0 {
+		return errors.New(failures[0])
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+var flowConfiguration = []byte(
+	`
+url: local
+
+endpoints:
+  create:
+    method: POST
+    path: /repos
+    body: 'name={name}'
+  get:
+    path: /repos/{name}
+  delete:
+    method: DELETE
+    path: /repos/{name}
+
+requests:
+  create_repo:
+    endpoint: create
+    name: gohit
+    expect:
+      status: 201
+
+flows:
+  lifecycle:
+    - create_repo
+    - request: get
+      variables:
+        name: gohit
+    - request: delete
+      variables:
+        name: gohit
+`)
+
+func TestLoadFlows(t *testing.T) {
+	reader := &MockReader{configurations: map[string][]byte{"test": flowConfiguration}}
+
+	conf, err := NewConfiguration(reader)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	flow := conf.Flows["lifecycle"]
+	if flow == nil || len(flow.Steps) != 3 ||
+		flow.Steps[0].Request != "create_repo" ||
+		flow.Steps[1].Request != "get" ||
+		flow.Steps[1].Variables["name"] != "gohit" {
+		t.Errorf("Flow configuration problem %v", flow)
+	}
+}
+
+func TestFlowWithMissingRequest(t *testing.T) {
+	reader := &MockReader{configurations: make(map[string][]byte)}
+	reader.configurations["test"] = []byte(
+		`
+url: local
+
+endpoints:
+  test:
+    path: /test
+
+flows:
+  broken:
+    - test
+    - missing
+`)
+
+	if _, err := NewConfiguration(reader); err == nil || err.Error() != "Flow 'broken' step 2 couldn't find request missing" {
+		t.Error("Should have thrown a missing request error but got ", err)
+	}
+}
+
+func TestRunFlow(t *testing.T) {
+	conf, err := NewConfiguration(&MockReader{configurations: map[string][]byte{"test": flowConfiguration}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	runner := &MockResponseRunner{responses: map[string]*Response{
+		"create_repo": {StatusCode: 201, Status: "201 Created"},
+		"get":         {StatusCode: 200, Status: "200 OK"},
+		"delete":      {StatusCode: 204, Status: "204 No Content"},
+	}}
+
+	var b bytes.Buffer
+	if err := NewFlowRunner(NewExecutor(conf, runner, &MockVariableReader{}), &b).Run("lifecycle"); err != nil {
+		t.Error("Should not throw an error ", err)
+	}
+	if len(runner.executed) != 3 || runner.executed[1].Path != "/repos/gohit" || runner.executed[0].Body != "name=gohit" {
+		t.Errorf("Should have run every step with its variables %v", runner.executed)
+	}
+	if flowOutput != strings.Trim(b.String(), " \n\t") {
+		t.Errorf("Flow output doesn't look correct %v", b.String())
+	}
+}
+
+func TestRunFlowStopsOnFailure(t *testing.T) {
+	conf, err := NewConfiguration(&MockReader{configurations: map[string][]byte{"test": flowConfiguration}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	runner := &MockResponseRunner{responses: map[string]*Response{
+		"create_repo": {StatusCode: 201, Status: "201 Created"},
+		"get":         {StatusCode: 404, Status: "404 Not Found"},
+	}}
+
+	var b bytes.Buffer
+	err = NewFlowRunner(NewExecutor(conf, runner, &MockVariableReader{}), &b).Run("lifecycle")
+	if err == nil || err.Error() != "Flow lifecycle failed" {
+		t.Error("Should have thrown a failed flow error but got ", err)
+	}
+	if len(runner.executed) != 2 {
+		t.Error("Should have stopped after the failed step")
+	}
+	if flowFailedOutput != strings.Trim(b.String(), " \n\t") {
+		t.Errorf("Flow output doesn't look correct %v", b.String())
+	}
+}
+
+func TestShowFlows(t *testing.T) {
+	conf, _ := NewConfiguration(&MockReader{configurations: map[string][]byte{"test": flowConfiguration}})
+
+	var b bytes.Buffer
+	printer := &Printer{conf: conf, writer: &b}
+	printer.ShowFlows()
+
+	if showFlowsOutput != strings.Trim(b.String(), " \n\t") {
+		t.Errorf("Flows output doesn't look correct %v", b.String())
+	}
+}
+
+var flowOutput = `1.  create_repo  201 Created     OK  0s
+2.  get          200 OK          OK  0s
+3.  delete       204 No Content  OK  0s
+
+Flow lifecycle finished, 3 steps`
+
+var flowFailedOutput = `1.  create_repo  201 Created    OK    0s
+2.  get          404 Not Found  FAIL  status 404
+
+Flow lifecycle stopped at step 2 of 3`
+
+var showFlowsOutput = `Flow lifecycle:
+        1. create_repo
+        2. get
+        3. delete`
@@ -5,4 +5,5 @@ go 1.15
 require (
 	github.com/smallfish/simpleyaml v0.0.0-20170911015856-a32031077861
 	github.com/urfave/cli v1.22.5
+	gopkg.in/yaml.v2 v2.2.2
 )
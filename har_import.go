@@ -0,0 +1,242 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// harIdSegment matches the path segments taken as ids: numbers, uuids and long hex strings.
+var harIdSegment = regexp.MustCompile(`^(\d+|[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}|[0-9a-fA-F]{24,})$`)
+
+// headers set by the browser or by curl itself, not imported.
+var harIgnoredHeaders = map[string]bool{"host": true, "content-length": true, "connection": true, "accept-encoding": true}
+
+// resources loaded by the browser that aren't api calls.
+var harStaticResources = map[string]bool{"image": true, "stylesheet": true, "script": true, "font": true, "media": true, "manifest": true}
+
+type harFile struct {
+	Log struct {
+		Entries []*harEntry `json:"entries"`
+	} `json:"log"`
+}
+
+type harEntry struct {
+	ResourceType string     `json:"_resourceType"`
+	Request      harRequest `json:"request"`
+}
+
+type harRequest struct {
+	Method   string       `json:"method"`
+	Url      string       `json:"url"`
+	Headers  []*harHeader `json:"headers"`
+	PostData *struct {
+		MimeType string `json:"mimeType"`
+		Text     string `json:"text"`
+	} `json:"postData"`
+}
+
+type harHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// harCall is one distinct request of a har file, matched to an endpoint by its path template.
+type harCall struct {
+	url        *url.URL
+	method     string
+	headers    []string
+	body       string
+	variables  map[interface{}]interface{}
+	template   string
+	queryNames []string
+	// the variable of each query parameter, different from its name when not a valid variable
+	queryVariables map[string]string
+}
+
+// ImportHar creates an endpoint for each host, method, path template and query parameters of the
+// entries of a har file, with a request for each distinct call. Ids in the path become variables.
+// Only the urls matching include, when given, are imported.
+func ImportHar(source []byte, include *regexp.Regexp) (*ConfigurationWriter, error) {
+	har := &harFile{}
+	if err := json.Unmarshal(source, har); err != nil {
+		return nil, err
+	}
+
+	var groups []string
+	calls := make(map[string][]*harCall)
+	seen := make(map[string]bool)
+	for _, entry := range har.Log.Entries {
+		if harStaticResources[entry.ResourceType] || (include != nil && !include.MatchString(entry.Request.Url)) {
+			continue
+		}
+		call, err := newHarCall(&entry.Request)
+		if err != nil {
+			return nil, err
+		}
+		if call == nil {
+			continue
+		}
+		identity := call.method + " " + call.url.String() + " " + call.body
+		if seen[identity] {
+			continue
+		}
+		seen[identity] = true
+
+		group := call.method + " " + call.url.Scheme + "://" + call.url.Host + call.template + "?" + strings.Join(call.queryNames, "&")
+		if calls[group] == nil {
+			groups = append(groups, group)
+		}
+		calls[group] = append(calls[group], call)
+	}
+	if len(groups) == 0 {
+		return nil, errors.New("No requests found in har file")
+	}
+
+	writer := &ConfigurationWriter{}
+	names := make(map[string]bool)
+	hosts := make(map[string]bool)
+	for _, group := range groups {
+		endpoint := harEndpoint(calls[group])
+		endpoint.Name = uniqueName(names, nameFromPath(endpoint.Method, variablePattern.ReplaceAllStringFunc(endpoint.Path, func(v string) string {
+			return strings.Trim(v, "{}")
+		})))
+		writer.Endpoints = append(writer.Endpoints, endpoint)
+		hosts[endpoint.Url] = true
+
+		for i, call := range calls[group] {
+			parameters := call.variables
+			parameters[ENDPOINT] = endpoint.Name
+			if call.body != "" {
+				parameters[BODY] = call.body
+			}
+			if headers := harRequestHeaders(endpoint, call); len(headers) > 0 {
+				parameters[HEADERS] = headers
+			}
+			writer.Requests = append(writer.Requests, &Request{Name: endpoint.Name + "_" + strconv.Itoa(i+1), Parameters: parameters})
+		}
+	}
+
+	if len(hosts) == 1 {
+		writer.Url = writer.Endpoints[0].Url
+		for _, endpoint := range writer.Endpoints {
+			endpoint.Url = ""
+		}
+	}
+	return writer, nil
+}
+
+func newHarCall(request *harRequest) (*harCall, error) {
+	parsed, err := url.Parse(request.Url)
+	if err != nil {
+		return nil, err
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return nil, nil
+	}
+	call := &harCall{
+		url:            parsed,
+		method:         strings.ToUpper(request.Method),
+		variables:      make(map[interface{}]interface{}),
+		queryVariables: make(map[string]string),
+	}
+
+	segments := strings.Split(parsed.EscapedPath(), "/")
+	for i := range segments {
+		if !harIdSegment.MatchString(segments[i]) {
+			continue
+		}
+		name := "id"
+		if i > 0 && segments[i-1] != "" && !strings.HasPrefix(segments[i-1], "{") {
+			name = strings.TrimSuffix(nonVariableCharacters.ReplaceAllString(segments[i-1], "_"), "s") + "_id"
+		}
+		name = uniqueVariable(call.variables, name)
+		call.variables[name] = segments[i]
+		segments[i] = "{" + name + "}"
+	}
+	call.template = strings.Join(segments, "/")
+	if call.template == "" {
+		call.template = "/"
+	}
+
+	query := parsed.Query()
+	for name := range query {
+		call.queryNames = append(call.queryNames, name)
+	}
+	sort.Strings(call.queryNames)
+	for _, name := range call.queryNames {
+		variable := uniqueVariable(call.variables, nonVariableCharacters.ReplaceAllString(name, "_"))
+		call.queryVariables[name] = variable
+		call.variables[variable] = query.Get(name)
+	}
+
+	for _, header := range request.Headers {
+		if strings.HasPrefix(header.Name, ":") || harIgnoredHeaders[strings.ToLower(header.Name)] {
+			continue
+		}
+		call.headers = append(call.headers, header.Name+": "+header.Value)
+	}
+	if request.PostData != nil {
+		call.body = request.PostData.Text
+	}
+	return call, nil
+}
+
+// harEndpoint uses the query names as variables and keeps the headers sent by every call.
+func harEndpoint(calls []*harCall) *Endpoint {
+	first := calls[0]
+	endpoint := &Endpoint{
+		Url:        first.url.Scheme + "://" + first.url.Host,
+		Path:       first.template,
+		Method:     first.method,
+		QueryList:  make(map[string]string),
+		Parameters: make(map[string]interface{}),
+	}
+	for _, name := range first.queryNames {
+		endpoint.QueryList[name] = "{" + first.queryVariables[name] + "}"
+		endpoint.QueryListKeys = append(endpoint.QueryListKeys, name)
+	}
+	for _, header := range first.headers {
+		shared := true
+		for _, call := range calls[1:] {
+			shared = shared && containsHeader(call.headers, header)
+		}
+		if shared {
+			endpoint.Headers = append(endpoint.Headers, header)
+		}
+	}
+	return endpoint
+}
+
+// harRequestHeaders returns the headers of a call not already sent by its endpoint.
+func harRequestHeaders(endpoint *Endpoint, call *harCall) []string {
+	var headers []string
+	for _, header := range call.headers {
+		if !containsHeader(endpoint.Headers, header) {
+			headers = append(headers, header)
+		}
+	}
+	return headers
+}
+
+func containsHeader(headers []string, header string) bool {
+	for i := range headers {
+		if headers[i] == header {
+			return true
+		}
+	}
+	return false
+}
+
+// uniqueVariable returns a name not used by the variables nor by the request attributes.
+func uniqueVariable(variables map[interface{}]interface{}, name string) string {
+	unique := name
+	for i := 2; variables[unique] != nil || requestAttributes[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	return unique
+}
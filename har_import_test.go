@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestImportHar(t *testing.T) {
+	source, _ := ioutil.ReadFile("_resources/har/session.har")
+	writer, err := ImportHar(source, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if writer.Url != "https://api.example.com" {
+		t.Errorf("Single host should be the global url %v", writer.Url)
+	}
+	if len(writer.Endpoints) != 2 {
+		t.Errorf("Expected 2 endpoints, got %v", len(writer.Endpoints))
+		return
+	}
+
+	orders := writer.Endpoints[0]
+	if orders.Name != "get_users_user_id_orders" || orders.Path != "/users/{user_id}/orders" || orders.QueryList["page"] != "{page}" {
+		t.Errorf("Unexpected endpoint %v", orders)
+	}
+	if !reflect.DeepEqual(orders.Headers, []string{"Accept: application/json"}) {
+		t.Errorf("Only shared headers expected %v", orders.Headers)
+	}
+
+	items := writer.Endpoints[1]
+	if items.Path != "/users/{user_id}/orders/{order_id}/items" || items.Method != "POST" {
+		t.Errorf("Unexpected endpoint %v", items)
+	}
+
+	if len(writer.Requests) != 3 {
+		t.Errorf("Duplicated calls should be ignored, got %v requests", len(writer.Requests))
+		return
+	}
+	second := writer.Requests[1].Parameters
+	if second["user_id"] != "34" || second["page"] != "2" || !reflect.DeepEqual(second[HEADERS], []string{"Authorization: Bearer def"}) {
+		t.Errorf("Unexpected request %v", second)
+	}
+	if writer.Requests[2].Parameters[BODY] != `{"count": 2}` {
+		t.Errorf("Unexpected body %v", writer.Requests[2].Parameters[BODY])
+	}
+}
+
+func TestImportHarInclude(t *testing.T) {
+	source, _ := ioutil.ReadFile("_resources/har/session.har")
+	writer, _ := ImportHar(source, regexp.MustCompile(`/items$`))
+	if len(writer.Endpoints) != 1 || writer.Endpoints[0].Method != "POST" {
+		t.Errorf("Unexpected endpoints %v", writer.Endpoints)
+	}
+
+	if _, err := ImportHar(source, regexp.MustCompile(`nothing`)); err == nil {
+		t.Error("Should fail without requests")
+	}
+}
+
+func TestImportHarAttributeNames(t *testing.T) {
+	writer, err := ImportHar([]byte(`{"log": {"entries": [
+		{"request": {"method": "GET", "url": "https://api.example.com/items?data=1&endpoint=2&body=3", "headers": []}}
+	]}}`), nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	endpoint := writer.Endpoints[0]
+	if !reflect.DeepEqual(endpoint.QueryList, map[string]string{"body": "{body2}", "data": "{data2}", "endpoint": "{endpoint2}"}) {
+		t.Errorf("Should have renamed the variables named like request attributes %v", endpoint.QueryList)
+	}
+	parameters := writer.Requests[0].Parameters
+	if parameters["data2"] != "1" || parameters["endpoint2"] != "2" || parameters["body2"] != "3" || parameters[ENDPOINT] != endpoint.Name {
+		t.Errorf("Unexpected request %v", parameters)
+	}
+}
+
+func TestImportHarLoadable(t *testing.T) {
+	source, _ := ioutil.ReadFile("_resources/har/session.har")
+	writer, _ := ImportHar(source, nil)
+
+	var b bytes.Buffer
+	writer.Write(&b)
+
+	conf, err := NewConfiguration(&MockReader{configurations: map[string][]byte{"test": b.Bytes()}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	request := conf.Requests["get_users_user_id_orders_2"]
+	if request.Url != "https://api.example.com" || request.Path != "/users/34/orders" || request.QueryList["page"] != "2" {
+		t.Errorf("Unexpected request %v", request)
+	}
+	if !reflect.DeepEqual(request.Headers, []string{"Accept: application/json", "Authorization: Bearer def"}) {
+		t.Errorf("Unexpected headers %v", request.Headers)
+	}
+}
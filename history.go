@@ -0,0 +1,347 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	// HISTORY_DIRECTORY is where the executions are kept, relative to the yaml directory.
+	HISTORY_DIRECTORY = ".gohit/history"
+	// DEFAULT_HISTORY_LIMIT is how many executions are kept unless the yaml sets history.
+	DEFAULT_HISTORY_LIMIT = 100
+
+	// HISTORY_REDACTED replaces the values of the sensitive headers in the history.
+	HISTORY_REDACTED = "<redacted>"
+
+	historyIdFormat = "20060102-150405.000000"
+)
+
+// sensitiveHeaders are not kept in the history, unless their secrets come from environment variables.
+var sensitiveHeaders = map[string]bool{"authorization": true, "proxy-authorization": true, "cookie": true}
+
+// sensitiveOptions are the curl options whose values aren't kept in the history, like sensitiveHeaders.
+var sensitiveOptions = map[string]bool{"-u": true, "--user": true, "-U": true, "--proxy-user": true, "--oauth2-bearer": true}
+
+// HistoryStore keeps the executions of requests, one json file each, removing the
+// oldest ones once there are more than limit.
+type HistoryStore struct {
+	mutex     sync.Mutex
+	directory string
+	limit     int
+}
+
+// HistoryEntry is one execution: the resolved request and the response it got.
+type HistoryEntry struct {
+	Id          string           `json:"id"`
+	Time        time.Time        `json:"time"`
+	Name        string           `json:"name"`
+	Environment string           `json:"environment,omitempty"`
+	Request     *historyRequest  `json:"request"`
+	Response    *historyResponse `json:"response"`
+}
+
+type historyRequest struct {
+	Method        string            `json:"method"`
+	Url           string            `json:"url"`
+	Path          string            `json:"path"`
+	QueryRaw      string            `json:"query_raw,omitempty"`
+	QueryList     map[string]string `json:"query,omitempty"`
+	QueryListKeys []string          `json:"query_keys,omitempty"`
+	Headers       []string          `json:"headers,omitempty"`
+	Options       []string          `json:"options,omitempty"`
+	Body          string            `json:"body,omitempty"`
+}
+
+type historyResponse struct {
+	Status     string      `json:"status,omitempty"`
+	StatusCode int         `json:"status_code,omitempty"`
+	Headers    http.Header `json:"headers,omitempty"`
+	Body       string      `json:"body"`
+	Stderr     string      `json:"stderr,omitempty"`
+	ElapsedMs  float64     `json:"elapsed_ms"`
+}
+
+func NewHistoryStore(directory string, limit int) *HistoryStore {
+	return &HistoryStore{directory: directory, limit: limit}
+}
+
+// Add saves the execution of a response, its Request being the resolved request.
+func (store *HistoryStore) Add(name string, environment string, response *Response) (*HistoryEntry, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	if store.limit <= 0 {
+		return nil, nil
+	}
+	if err := os.MkdirAll(store.directory, 0700); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	id := historyId(now)
+	for store.exists(id) {
+		now = now.Add(time.Microsecond)
+		id = historyId(now)
+	}
+	entry := newHistoryEntry(id, now, name, environment, response)
+	asJson, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(store.file(id), asJson, 0600); err != nil {
+		return nil, err
+	}
+	return entry, store.prune()
+}
+
+// List returns the executions from the oldest to the most recent.
+func (store *HistoryStore) List() ([]*HistoryEntry, error) {
+	ids, err := store.ids()
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]*HistoryEntry, 0, len(ids))
+	for _, id := range ids {
+		entry, err := store.Get(id)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// Get returns an execution by id, or the most recent one when the id is empty.
+func (store *HistoryStore) Get(id string) (*HistoryEntry, error) {
+	if id == "" {
+		ids, err := store.ids()
+		if err != nil {
+			return nil, err
+		}
+		if len(ids) == 0 {
+			return nil, errors.New("History is empty")
+		}
+		id = ids[len(ids)-1]
+	}
+	source, err := ioutil.ReadFile(store.file(id))
+	if os.IsNotExist(err) {
+		return nil, errors.New(fmt.Sprintf("Could not find history entry %v", id))
+	} else if err != nil {
+		return nil, err
+	}
+	entry := &HistoryEntry{}
+	if err := json.Unmarshal(source, entry); err != nil {
+		return nil, errors.New(fmt.Sprintf("Invalid history entry %v: %v", id, err))
+	}
+	return entry, nil
+}
+
+// ids are sorted from the oldest to the most recent, as they are made of the time.
+func (store *HistoryStore) ids() ([]string, error) {
+	files, err := ioutil.ReadDir(store.directory)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, file := range files {
+		if !file.IsDir() && strings.HasSuffix(file.Name(), ".json") {
+			ids = append(ids, strings.TrimSuffix(file.Name(), ".json"))
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+func (store *HistoryStore) prune() error {
+	ids, err := store.ids()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(ids)-store.limit; i++ {
+		if err := os.Remove(store.file(ids[i])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (store *HistoryStore) exists(id string) bool {
+	_, err := os.Stat(store.file(id))
+	return err == nil
+}
+
+func (store *HistoryStore) file(id string) string {
+	return filepath.Join(store.directory, filepath.Base(id)+".json")
+}
+
+func historyId(t time.Time) string {
+	return strings.Replace(t.UTC().Format(historyIdFormat), ".", "-", 1)
+}
+
+func newHistoryEntry(id string, t time.Time, name string, environment string, response *Response) *HistoryEntry {
+	entry := &HistoryEntry{
+		Id:          id,
+		Time:        t.Round(time.Millisecond),
+		Name:        name,
+		Environment: environment,
+		Response: &historyResponse{
+			Status:     response.Status,
+			StatusCode: response.StatusCode,
+			Headers:    response.Headers,
+			Body:       string(response.Body),
+			Stderr:     withoutProgressMeter(response.Stderr),
+			ElapsedMs:  float64(response.Elapsed.Microseconds()) / 1000,
+		},
+	}
+	if response.Request != nil {
+		request := redactedRequest(response.Request)
+		entry.Request = &historyRequest{
+			Method:        request.Method,
+			Url:           request.Url,
+			Path:          request.Path,
+			QueryRaw:      request.QueryRaw,
+			QueryList:     request.QueryList,
+			QueryListKeys: request.QueryListKeys,
+			Headers:       request.Headers,
+			Options:       request.Options,
+			Body:          request.Body,
+		}
+	}
+	return entry
+}
+
+// redactedRequest returns a copy of a resolved request fit to be kept: the values of the
+// environment variables are back to their placeholders and the sensitive headers are hidden.
+func redactedRequest(request *Request) *Request {
+	redacted := request.copy()
+	values := make([]string, 0, len(request.secrets))
+	for value := range request.secrets {
+		values = append(values, value)
+	}
+	// the longest first, so a value containing another one is replaced whole
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+	for _, value := range values {
+		replaceInRequest(redacted, value, request.secrets[value])
+	}
+	for i, header := range redacted.Headers {
+		if sensitiveHeaders[headerName(header)] && !environmentVariablePattern.MatchString(header) {
+			redacted.Headers[i] = header[:strings.Index(header, ":")+1] + " " + HISTORY_REDACTED
+		}
+	}
+	redacted.Options = redactedOptions(redacted.Options)
+	return redacted
+}
+
+// redactedOptions replaces the values of the sensitive options, given as '-u value', '--user=value'
+// or '-uvalue', even in the next option, unless they come from environment variables.
+func redactedOptions(options []string) []string {
+	redacted := make([]string, len(options))
+	sensitive := false
+	for i, option := range options {
+		redacted[i] = ""
+		last := 0
+		for _, span := range optionTokenPattern.FindAllStringIndex(option, -1) {
+			token := option[span[0]:span[1]]
+			start := span[0]
+			if sensitive {
+				sensitive = false
+			} else if k := strings.Index(token, "="); k > 0 && sensitiveOptions[token[:k]] && k < len(token)-1 {
+				start += k + 1
+			} else if len(token) > 2 && !strings.HasPrefix(token, "--") && sensitiveOptions[token[:2]] {
+				start += 2
+			} else {
+				sensitive = sensitiveOptions[strings.TrimSuffix(token, "=")]
+				continue
+			}
+			if !environmentVariablePattern.MatchString(token) {
+				redacted[i] += option[last:start] + HISTORY_REDACTED
+				last = span[1]
+			}
+		}
+		redacted[i] += option[last:]
+	}
+	return redacted
+}
+
+func isRedactedHeader(header string) bool {
+	return sensitiveHeaders[headerName(header)] && strings.HasSuffix(header, ": "+HISTORY_REDACTED)
+}
+
+// AsRequest returns the request of the execution, with the environment placeholders and the
+// redacted headers as they were kept.
+func (entry *HistoryEntry) AsRequest() *Request {
+	request := &Request{Name: entry.Name, QueryList: make(map[string]string), Parameters: make(map[interface{}]interface{})}
+	if saved := entry.Request; saved != nil {
+		request.Method = saved.Method
+		request.Url = saved.Url
+		request.Path = saved.Path
+		request.QueryRaw = saved.QueryRaw
+		request.QueryListKeys = saved.QueryListKeys
+		request.Headers = append([]string(nil), saved.Headers...)
+		request.Options = append([]string(nil), saved.Options...)
+		request.Body = saved.Body
+		for key, value := range saved.QueryList {
+			request.QueryList[key] = value
+		}
+	}
+	return request
+}
+
+// AsResponse returns the response of the execution, as printed by run.
+func (entry *HistoryEntry) AsResponse() *Response {
+	response := &Response{Request: entry.AsRequest(), Headers: make(http.Header)}
+	if saved := entry.Response; saved != nil {
+		response.Status = saved.Status
+		response.StatusCode = saved.StatusCode
+		response.Body = []byte(saved.Body)
+		response.Stderr = []byte(saved.Stderr)
+		response.Elapsed = time.Duration(saved.ElapsedMs * float64(time.Millisecond))
+		for name, values := range saved.Headers {
+			response.Headers[name] = values
+		}
+	}
+	return response
+}
+
+// ShowHistory lists the executions, only those of the given request or endpoint when a name is given.
+func ShowHistory(store *HistoryStore, name string, writer io.Writer) error {
+	entries, err := store.List()
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(writer, 0, 8, 2, ' ', 0)
+	for _, entry := range entries {
+		if name != "" && entry.Name != name {
+			continue
+		}
+		environment := entry.Environment
+		if environment == "" {
+			environment = "-"
+		}
+		status := "-"
+		elapsed := time.Duration(0)
+		if entry.Response != nil {
+			if entry.Response.StatusCode != 0 {
+				status = fmt.Sprint(entry.Response.StatusCode)
+			}
+			elapsed = time.Duration(entry.Response.ElapsedMs * float64(time.Millisecond))
+		}
+		response := entry.AsResponse()
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v %v\t%v\t%v\n", entry.Id, entry.Time.Local().Format("2006-01-02 15:04:05"),
+			entry.Name, environment, response.Request.Method, requestUrl(response.Request), status, elapsed.Round(time.Millisecond))
+	}
+	return w.Flush()
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHistoryStore(t *testing.T) {
+	directory, _ := ioutil.TempDir("", "gohit")
+	defer os.RemoveAll(directory)
+	store := NewHistoryStore(filepath.Join(directory, HISTORY_DIRECTORY), 2)
+
+	response := &Response{
+		Request:    &Request{Name: "get_user", Method: "GET", Url: "http://localhost", Path: "/users/1", QueryList: map[string]string{"a": "1"}, QueryListKeys: []string{"a"}, Headers: []string{"Accept: application/json"}},
+		Status:     "200 OK",
+		StatusCode: 200,
+		Headers:    http.Header{"Content-Type": []string{"application/json"}},
+		Body:       []byte(`{"id":1}`),
+		Elapsed:    25 * time.Millisecond,
+	}
+	var ids []string
+	for i := 0; i < 3; i++ {
+		entry, err := store.Add("get_user", "staging", response)
+		if err != nil {
+			t.Error("Should not throw an error ", err)
+			return
+		}
+		ids = append(ids, entry.Id)
+	}
+
+	entries, err := store.List()
+	if err != nil {
+		t.Error("Should not throw an error ", err)
+		return
+	}
+	if len(entries) != 2 || entries[0].Id != ids[1] || entries[1].Id != ids[2] {
+		t.Errorf("Should have kept the 2 most recent executions %v but got %v", ids[1:], entries)
+	}
+
+	last, err := store.Get("")
+	if err != nil || last.Id != ids[2] || last.Environment != "staging" {
+		t.Errorf("Should have returned the most recent execution but got %v %v", last, err)
+		return
+	}
+	saved := last.AsResponse()
+	if saved.StatusCode != 200 || string(saved.Body) != `{"id":1}` || saved.Elapsed != 25*time.Millisecond ||
+		saved.Headers.Get("Content-Type") != "application/json" {
+		t.Errorf("Unexpected response %v", saved)
+	}
+	if requestUrl(saved.Request) != "http://localhost/users/1?a=1" || !reflect.DeepEqual(saved.Request.Headers, response.Request.Headers) {
+		t.Errorf("Unexpected request %v", saved.Request)
+	}
+
+	if _, err := store.Get(ids[0]); err == nil || err.Error() != "Could not find history entry "+ids[0] {
+		t.Error("Should have removed the oldest execution but got ", err)
+	}
+}
+
+func TestHistoryDisabled(t *testing.T) {
+	directory, _ := ioutil.TempDir("", "gohit")
+	defer os.RemoveAll(directory)
+	store := NewHistoryStore(filepath.Join(directory, HISTORY_DIRECTORY), 0)
+
+	if entry, err := store.Add("test", "", &Response{}); entry != nil || err != nil {
+		t.Error("Should not keep executions")
+	}
+	if _, err := store.Get(""); err == nil || err.Error() != "History is empty" {
+		t.Error("Should throw an empty history error but got ", err)
+	}
+}
+
+func TestExecutorHistory(t *testing.T) {
+	reader := &MockReader{configurations: make(map[string][]byte)}
+	reader.configurations["test"] = []byte(
+		`
+url: local
+history: 5
+
+endpoints:
+  test:
+    path: /test/{id}
+
+requests:
+  my_request:
+    endpoint: test
+    id: 1
+`)
+
+	conf, err := NewEnvironmentConfiguration(reader, "")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if conf.HistoryLimit != 5 {
+		t.Errorf("Should have read the history limit but got %v", conf.HistoryLimit)
+	}
+	directory, _ := ioutil.TempDir("", "gohit")
+	defer os.RemoveAll(directory)
+	runner := &MockResponseRunner{responses: map[string]*Response{"my_request": {StatusCode: 201, Body: []byte("created")}}}
+	executor := NewExecutor(conf, runner, &MockVariableReader{})
+	store := NewHistoryStore(directory, conf.HistoryLimit)
+	executor.SetHistory(store)
+
+	if _, err := executor.ExecuteRequest("my_request", nil); err != nil {
+		t.Error("Should not throw an error ", err)
+		return
+	}
+	entry, err := store.Get("")
+	if err != nil {
+		t.Error("Should not throw an error ", err)
+		return
+	}
+	if entry.Name != "my_request" || entry.Request.Path != "/test/1" || entry.Response.StatusCode != 201 || entry.Response.Body != "created" {
+		t.Errorf("Unexpected history entry %v", entry)
+	}
+
+	if _, err := executor.Rerun(entry); err != nil {
+		t.Error("Should not throw an error ", err)
+	}
+	if len(runner.executed) != 2 || runner.executed[1].Path != "/test/1" || runner.executed[1].Url != "local" {
+		t.Errorf("Should have run the saved request again but got %v", runner.executed)
+	}
+
+	var b bytes.Buffer
+	if err := ShowHistory(store, "other", &b); err != nil || b.String() != "" {
+		t.Errorf("Should have filtered the executions by name but got %v", b.String())
+	}
+	if err := ShowHistory(store, "my_request", &b); err != nil || strings.Count(b.String(), "my_request  -  GET local/test/1  201") != 2 {
+		t.Errorf("Unexpected history %v", b.String())
+	}
+}
+
+func TestHistoryRedacted(t *testing.T) {
+	reader := &MockReader{configurations: make(map[string][]byte), dotEnv: map[string]string{"GOHIT_TEST_KEY": "secret-key", "GOHIT_TEST_BODY": "secret-body"}}
+	reader.configurations["test"] = []byte(
+		`
+url: local
+
+endpoints:
+  test:
+    path: /test
+    headers:
+      - 'Authorization: Bearer {token}'
+      - 'Cookie: id=abc'
+      - 'X-Api-Key: ${GOHIT_TEST_KEY}'
+    body: 'key={env:GOHIT_TEST_BODY}'
+`)
+
+	conf, err := NewConfiguration(reader)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	conf.Session.Set("token", "captured-token")
+	directory, _ := ioutil.TempDir("", "gohit")
+	defer os.RemoveAll(directory)
+	runner := &MockResponseRunner{}
+	executor := NewExecutor(conf, runner, &MockVariableReader{})
+	store := NewHistoryStore(directory, 5)
+	executor.SetHistory(store)
+
+	if _, err := executor.ExecuteRequest("test", nil); err != nil {
+		t.Error("Should not throw an error ", err)
+		return
+	}
+	source, _ := ioutil.ReadDir(directory)
+	saved, _ := ioutil.ReadFile(filepath.Join(directory, source[0].Name()))
+	if strings.Contains(string(saved), "secret-") || strings.Contains(string(saved), "captured-token") || strings.Contains(string(saved), "id=abc") {
+		t.Errorf("Should not have kept the secrets but got %v", string(saved))
+	}
+	entry, _ := store.Get("")
+	expected := []string{"Authorization: " + HISTORY_REDACTED, "Cookie: " + HISTORY_REDACTED, "X-Api-Key: ${GOHIT_TEST_KEY}"}
+	if !reflect.DeepEqual(entry.Request.Headers, expected) || entry.Request.Body != "key={env:GOHIT_TEST_BODY}" {
+		t.Errorf("Unexpected request %v %v", entry.Request.Headers, entry.Request.Body)
+	}
+
+	if _, err := executor.Rerun(entry); err != nil {
+		t.Error("Should not throw an error ", err)
+		return
+	}
+	rerun := runner.executed[1]
+	expected = []string{"Authorization: Bearer captured-token", "Cookie: id=abc", "X-Api-Key: secret-key"}
+	if !reflect.DeepEqual(rerun.Headers, expected) || rerun.Body != "key=secret-body" {
+		t.Errorf("Should have resolved the secrets again but got %v %v", rerun.Headers, rerun.Body)
+	}
+
+	conf.Session.Clear()
+	if _, err := executor.Rerun(entry); err == nil ||
+		err.Error() != "Could not rerun test, its Authorization header isn't kept in the history, run it with 'gohit run'" {
+		t.Error("Should have thrown a redacted header error but got ", err)
+	}
+}
+
+func TestHistoryRedactedOptions(t *testing.T) {
+	reader := &MockReader{configurations: make(map[string][]byte), dotEnv: map[string]string{"GOHIT_TEST_PROXY": "proxy:secret-proxy"}}
+	reader.configurations["test"] = []byte(
+		`
+url: local
+
+options:
+  - '-u admin:secret-1'
+  - '--oauth2-bearer'
+  - 'secret-2'
+  - '--max-time 5'
+
+endpoints:
+  test:
+    path: /test
+    options:
+      - "--user='a:secret-3'"
+      - '-Usecret-4'
+      - '--proxy-user ${GOHIT_TEST_PROXY}'
+`)
+
+	conf, err := NewConfiguration(reader)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	directory, _ := ioutil.TempDir("", "gohit")
+	defer os.RemoveAll(directory)
+	runner := &MockResponseRunner{}
+	executor := NewExecutor(conf, runner, &MockVariableReader{})
+	store := NewHistoryStore(directory, 5)
+	executor.SetHistory(store)
+
+	if _, err := executor.ExecuteRequest("test", nil); err != nil {
+		t.Error("Should not throw an error ", err)
+		return
+	}
+	source, _ := ioutil.ReadDir(directory)
+	saved, _ := ioutil.ReadFile(filepath.Join(directory, source[0].Name()))
+	if strings.Contains(string(saved), "secret-") {
+		t.Errorf("Should not have kept the credentials but got %v", string(saved))
+	}
+	entry, _ := store.Get("")
+	expected := []string{"-u " + HISTORY_REDACTED, "--oauth2-bearer", HISTORY_REDACTED, "--max-time 5",
+		"--user=" + HISTORY_REDACTED, "-U" + HISTORY_REDACTED, "--proxy-user ${GOHIT_TEST_PROXY}"}
+	if !reflect.DeepEqual(entry.Request.Options, expected) {
+		t.Errorf("Unexpected options %v", entry.Request.Options)
+	}
+
+	if _, err := executor.Rerun(entry); err != nil {
+		t.Error("Should not throw an error ", err)
+		return
+	}
+	expected = []string{"-u admin:secret-1", "--oauth2-bearer", "secret-2", "--max-time 5", "--user='a:secret-3'", "-Usecret-4", "--proxy-user proxy:secret-proxy"}
+	if rerun := runner.executed[1]; !reflect.DeepEqual(rerun.Options, expected) {
+		t.Errorf("Should have restored the options but got %v", rerun.Options)
+	}
+}
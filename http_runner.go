@@ -0,0 +1,186 @@
+package main
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HttpRunner executes requests with net/http instead of curl. It understands
+// only a subset of the curl options.
+type HttpRunner struct {
+}
+
+type httpSettings struct {
+	compress bool
+	insecure bool
+	follow   bool
+	user     string
+	timeout  time.Duration
+}
+
+func (runner *HttpRunner) Run(request *Request, command []string) (*Response, error) {
+	settings, err := readHttpSettings(request)
+	if err != nil {
+		return nil, err
+	}
+	httpRequest, err := newHttpRequest(request, settings)
+	if err != nil {
+		return nil, err
+	}
+	return doHttpRequest(newHttpClient(settings), httpRequest)
+}
+
+func doHttpRequest(client *http.Client, httpRequest *http.Request) (*Response, error) {
+	start := time.Now()
+	httpResponse, err := client.Do(httpRequest)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResponse.Body.Close()
+
+	body, err := ioutil.ReadAll(httpResponse.Body)
+	if err != nil {
+		return nil, err
+	}
+	return &Response{
+		Status:     httpResponse.Status,
+		StatusCode: httpResponse.StatusCode,
+		Headers:    httpResponse.Header,
+		Body:       body,
+		Elapsed:    time.Since(start),
+	}, nil
+}
+
+func readHttpSettings(request *Request) (*httpSettings, error) {
+	settings, unsupported, err := parseHttpSettings(request)
+	if err != nil {
+		return nil, err
+	}
+	if len(unsupported) > 0 {
+		return nil, errors.New(fmt.Sprintf("Option '%v' is not supported by the http runner", unsupported[0]))
+	}
+	return settings, nil
+}
+
+// parseHttpSettings also returns the options it doesn't understand.
+func parseHttpSettings(request *Request) (*httpSettings, []string, error) {
+	settings := &httpSettings{}
+	var unsupported []string
+	tokens := strings.Split(executableOptionsAsToken(request), "\n")
+	for i := 0; i < len(tokens); i++ {
+		option := tokens[i]
+		switch option {
+		case "":
+		case "--compress", "--compressed":
+			settings.compress = true
+		case "-s", "--silent":
+		case "-k", "--insecure":
+			settings.insecure = true
+		case "-L", "--location":
+			settings.follow = true
+		case "-u", "--user", "-m", "--max-time":
+			if i+1 == len(tokens) {
+				return nil, nil, errors.New(fmt.Sprintf("Option '%v' is missing its value", option))
+			}
+			i++
+			value := strings.Trim(tokens[i], "'\"")
+			if option == "-u" || option == "--user" {
+				settings.user = value
+			} else if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+				settings.timeout = time.Duration(seconds * float64(time.Second))
+			} else {
+				return nil, nil, errors.New(fmt.Sprintf("Invalid max time '%v'", value))
+			}
+		default:
+			unsupported = append(unsupported, option)
+		}
+	}
+	return settings, unsupported, nil
+}
+
+func newHttpClient(settings *httpSettings) *http.Client {
+	transport := &http.Transport{
+		Proxy:              http.ProxyFromEnvironment,
+		DisableCompression: !settings.compress,
+	}
+	if settings.insecure {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	client := &http.Client{Transport: transport, Timeout: settings.timeout}
+	if !settings.follow {
+		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+	return client
+}
+
+// requestUrl returns the url of a request with its query, the query list encoded as curl -G --data-urlencode does.
+func requestUrl(request *Request) string {
+	address := request.Url + request.Path
+	if request.QueryRaw != "" {
+		address = address + "?" + request.QueryRaw
+	} else if len(request.QueryListKeys) > 0 {
+		address = address + "?" + encodeQueryList(request.QueryListKeys, request.QueryList)
+	}
+	return address
+}
+
+// rawQueryList is the query list as written, without escaping.
+func rawQueryList(keys []string, list map[string]string) string {
+	query := make([]string, 0, len(keys))
+	for _, key := range keys {
+		query = append(query, key+"="+list[key])
+	}
+	return strings.Join(query, "&")
+}
+
+func encodeQueryList(keys []string, list map[string]string) string {
+	query := make([]string, 0, len(keys))
+	for _, key := range keys {
+		query = append(query, url.QueryEscape(key)+"="+url.QueryEscape(list[key]))
+	}
+	return strings.Join(query, "&")
+}
+
+func newHttpRequest(request *Request, settings *httpSettings) (*http.Request, error) {
+	httpRequest, err := http.NewRequest(request.Method, requestUrl(request), strings.NewReader(request.Body))
+	if err != nil {
+		return nil, err
+	}
+	if request.Body != "" {
+		httpRequest.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	for _, header := range request.Headers {
+		i := strings.Index(header, ":")
+		if i <= 0 {
+			continue
+		}
+		name := strings.TrimSpace(header[:i])
+		value := strings.TrimSpace(header[i+1:])
+		if strings.EqualFold(name, "Host") {
+			httpRequest.Host = value
+		} else if strings.EqualFold(name, "Content-Type") {
+			httpRequest.Header.Set(name, value)
+		} else {
+			httpRequest.Header.Add(name, value)
+		}
+	}
+
+	if settings.user != "" {
+		credentials := strings.SplitN(settings.user, ":", 2)
+		if len(credentials) == 1 {
+			credentials = append(credentials, "")
+		}
+		httpRequest.SetBasicAuth(credentials[0], credentials[1])
+	}
+	return httpRequest, nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHttpRunnerRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		user, password, _ := r.BasicAuth()
+		if r.Method != "POST" ||
+			r.URL.Path != "/test/value" ||
+			r.URL.RawQuery != "version=v2&format=json+xml" ||
+			r.Header.Get("Custom") != "value" ||
+			r.Header.Get("Content-Type") != "application/json" ||
+			user != "user" || password != "pass" ||
+			string(body) != `{"name":"gohit"}` {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("X-Test", "ok")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("created"))
+	}))
+	defer server.Close()
+
+	reader := &MockReader{configurations: make(map[string][]byte)}
+	reader.configurations["test"] = []byte(
+		`
+url: ` + server.URL + `
+
+runner: http
+
+headers:
+  - 'Custom: value'
+
+endpoints:
+  test:
+    method: POST
+    path: /test/{param}
+    query:
+      - version: v2
+      - format
+    headers:
+      - 'Content-Type: application/json'
+    options:
+      - '--silent'
+      - '-u user:pass'
+      - '--max-time 5'
+    body:
+      name: gohit
+
+requests:
+  my_request:
+    endpoint: test
+    param: value
+    format: json xml
+`)
+
+	conf, err := NewConfiguration(reader)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	response, err := NewDefaultExecutor(conf).ExecuteRequest("my_request", nil)
+	if err != nil {
+		t.Error("Should not throw an error ", err)
+		return
+	}
+	if response.StatusCode != 201 || response.Status != "201 Created" ||
+		response.Headers.Get("X-Test") != "ok" || string(response.Body) != "created" {
+		t.Errorf("Response doesn't look correct %v %v %v", response.StatusCode, response.Headers, string(response.Body))
+	}
+}
+
+func TestHttpRunnerFollowRedirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+			return
+		}
+		w.Write([]byte("new"))
+	}))
+	defer server.Close()
+
+	runner := &HttpRunner{}
+	request := &Request{Url: server.URL, Path: "/old", Method: "GET", Options: []string{}}
+	if response, err := runner.Run(request, nil); err != nil || response.StatusCode != 301 {
+		t.Error("Should not follow redirects without -L ", err)
+	}
+
+	request.Options = []string{"-L"}
+	if response, err := runner.Run(request, nil); err != nil || string(response.Body) != "new" {
+		t.Error("Should follow redirects with -L ", err)
+	}
+}
+
+func TestHttpRunnerUnsupportedOption(t *testing.T) {
+	runner := &HttpRunner{}
+	request := &Request{Url: "http://localhost", Path: "/", Method: "GET", Options: []string{"-vvv"}}
+
+	if _, err := runner.Run(request, nil); err == nil || err.Error() != "Option '-vvv' is not supported by the http runner" {
+		t.Error("Should have thrown an unsupported option error but got ", err)
+	}
+}
+
+func TestInvalidRunner(t *testing.T) {
+	reader := &MockReader{configurations: make(map[string][]byte)}
+	reader.configurations["test"] = []byte(
+		`
+url: local
+
+runner: wget
+
+endpoints:
+  test:
+    path: /test
+`)
+
+	if _, err := NewConfiguration(reader); err == nil || err.Error() != "Invalid runner 'wget'" {
+		t.Error("Should have thrown an invalid runner error but got ", err)
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+// jsonPath walks a decoded json document following a path like 'items[0].name',
+// 'items.0.name' or '$.items[0].name'.
+func jsonPath(document interface{}, path string) (interface{}, bool) {
+	current := document
+	for _, key := range splitJsonPath(path) {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, ok := node[key]
+			if !ok {
+				return nil, false
+			}
+			current = value
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			current = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+func splitJsonPath(path string) []string {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	path = strings.Replace(path, "[", ".", -1)
+	path = strings.Replace(path, "]", "", -1)
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, ".")
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestJsonPath(t *testing.T) {
+	var document interface{}
+	json.Unmarshal([]byte(`{"owner": {"login": "fabiofalci"}, "items": [{"id": 1}, {"id": 2}]}`), &document)
+
+	if value, found := jsonPath(document, "owner.login"); !found || value != "fabiofalci" {
+		t.Errorf("owner.login should be fabiofalci but got %v", value)
+	}
+
+	if value, found := jsonPath(document, "$.items[1].id"); !found || value != float64(2) {
+		t.Errorf("$.items[1].id should be 2 but got %v", value)
+	}
+
+	if value, found := jsonPath(document, "items.0.id"); !found || value != float64(1) {
+		t.Errorf("items.0.id should be 1 but got %v", value)
+	}
+
+	if _, found := jsonPath(document, "items[2].id"); found {
+		t.Error("items[2].id should not be found")
+	}
+
+	if _, found := jsonPath(document, "owner.login.name"); found {
+		t.Error("owner.login.name should not be found")
+	}
+}
@@ -3,41 +3,57 @@ package main
 import (
 	"fmt"
 	"github.com/urfave/cli"
+	"io/ioutil"
+	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
+	"regexp"
 	"runtime"
 	"strconv"
+	"strings"
 	"time"
 )
 
 type Endpoint struct {
-	Name       string
-	Url        string
-	Path       string
-	QueryRaw   string
-	QueryList  map[string]string
-	QueryListKeys  []string
-	Method     string
-	Headers    map[string]bool
-	Options    map[string]bool
-	Parameters map[string]interface{}
+	Name          string
+	Url           string
+	Path          string
+	QueryRaw      string
+	QueryList     map[string]string
+	QueryListKeys []string
+	Method        string
+	Headers       []string
+	Options       []string
+	Body          string
+	Parameters    map[string]interface{}
+	Mock          *MockResponse
 }
 
 type Request struct {
-	Name       string
-	Url        string
-	Path       string
-	QueryRaw   string
-	QueryList  map[string]string
-	QueryListKeys  []string
-	Method     string
-	Headers    map[string]bool
-	Options    map[string]bool
-	Parameters map[interface{}]interface{}
+	Name          string
+	Url           string
+	Path          string
+	QueryRaw      string
+	QueryList     map[string]string
+	QueryListKeys []string
+	Method        string
+	Headers       []string
+	Options       []string
+	Body          string
+	Expect        *Expectation
+	Capture       map[string]*Capture
+	Ignore        []string
+	Data          string
+	Parameters    map[interface{}]interface{}
+	// secrets are the placeholders of the environment variables resolved in the request, by value
+	secrets map[string]string
 }
 
 type Executable interface {
 	GetName() string
-	GetOptions() map[string]bool
+	GetOptions() []string
+	GetBody() string
 }
 
 var version string
@@ -59,6 +75,8 @@ func main() {
 	var file string
 	var directory string
 	var oneLine bool
+	var runner string
+	var environment string
 
 	app.Flags = []cli.Flag{
 		cli.StringFlag{
@@ -77,6 +95,80 @@ func main() {
 			Usage:       "Print commands in one line",
 			Destination: &oneLine,
 		},
+		cli.StringFlag{
+			Name:        "env, e",
+			Usage:       "Select one of the environments",
+			Destination: &environment,
+		},
+		cli.StringSliceFlag{
+			Name:  "var",
+			Usage: "Set a variable as name=value, overriding the yaml variables. Can be repeated",
+		},
+		cli.StringFlag{
+			Name:  "vars-file",
+			Usage: "Load variables from a yaml file, overriding the yaml variables",
+		},
+		cli.StringFlag{
+			Name:        "runner",
+			Usage:       "Execute requests with 'curl', the native 'http' client or 'replay' the recorded responses",
+			Destination: &runner,
+		},
+	}
+
+	loadConfiguration := func(c *cli.Context) (*Configuration, error) {
+		conf, err := NewEnvironmentConfiguration(NewDefaultConfigurationReader(directory, file), environment)
+		if err != nil {
+			return nil, err
+		}
+		variables, err := ReadVariables(c.GlobalStringSlice("var"), c.GlobalString("vars-file"))
+		if err != nil {
+			return nil, err
+		}
+		if len(variables) > 0 {
+			if err := conf.SetVariables(variables); err != nil {
+				return nil, err
+			}
+		}
+		return conf, nil
+	}
+
+	newExecutor := func(conf *Configuration) (*Executor, error) {
+		if runner != "" {
+			if err := conf.SetRunner(runner); err != nil {
+				return nil, err
+			}
+		}
+		session, err := NewFileVariableStore(filepath.Join(directory, SESSION_FILE))
+		if err != nil {
+			return nil, err
+		}
+		conf.Session = session
+		executor := NewDefaultExecutor(conf)
+		executor.SetHistory(NewHistoryStore(filepath.Join(directory, HISTORY_DIRECTORY), conf.HistoryLimit))
+		return executor, nil
+	}
+
+	outputFlags := func(output string) []cli.Flag {
+		return []cli.Flag{
+			cli.StringFlag{
+				Name:  "output, o",
+				Value: output,
+				Usage: "Print the response raw, pretty with status and timing or as a json document",
+			},
+			cli.StringFlag{
+				Name:  "color",
+				Value: COLOR_AUTO,
+				Usage: "Colour the pretty output: auto, always or never",
+			},
+			cli.StringSliceFlag{
+				Name:  "show-header",
+				Usage: "Response header shown by the pretty output, '*' for all. Can be repeated",
+			},
+		}
+	}
+
+	newResponsePrinter := func(c *cli.Context) (*ResponsePrinter, error) {
+		return NewResponsePrinter(os.Stdout, c.String("output"), c.String("color"), c.StringSlice("show-header"))
 	}
 
 	app.Commands = []cli.Command{
@@ -84,7 +176,7 @@ func main() {
 			Name:      "requests",
 			ShortName: "r",
 			Action: func(c *cli.Context) error {
-				conf, err := NewConfiguration(NewDefaultConfigurationReader(directory, file))
+				conf, err := loadConfiguration(c)
 				if err != nil {
 					return err
 				}
@@ -97,7 +189,7 @@ func main() {
 			Name:      "endpoints",
 			ShortName: "e",
 			Action: func(c *cli.Context) error {
-				conf, err := NewConfiguration(NewDefaultConfigurationReader(directory, file))
+				conf, err := loadConfiguration(c)
 				if err != nil {
 					return err
 				}
@@ -107,27 +199,750 @@ func main() {
 			},
 		},
 		{
-			Name: "show",
+			Name:  "envs",
+			Usage: "List the environments",
 			Action: func(c *cli.Context) error {
-				conf, err := NewConfiguration(NewDefaultConfigurationReader(directory, file))
+				conf, err := loadConfiguration(c)
 				if err != nil {
 					return err
 				}
 				printer := &Printer{conf: conf, writer: os.Stdout, oneLine: oneLine}
-				printer.ShowRequestOrEndpoint(c.Args().First())
+				printer.ShowEnvironments()
 				return nil
 			},
 		},
 		{
-			Name: "run",
+			Name: "show",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "as",
+					Usage: "Show the request for another client: " + strings.Join(ShowTargets(), ", "),
+				},
+			},
 			Action: func(c *cli.Context) error {
-				conf, err := NewConfiguration(NewDefaultConfigurationReader(directory, file))
+				if err := checkShowTarget(c.String("as")); err != nil {
+					return cli.NewExitError(err.Error(), 1)
+				}
+				conf, err := loadConfiguration(c)
 				if err != nil {
 					return err
 				}
-				executor := NewDefaultExecutor(conf)
+				printer := &Printer{conf: conf, writer: os.Stdout, oneLine: oneLine, as: c.String("as")}
+				printer.ShowRequestOrEndpoint(c.Args().First())
+				return nil
+			},
+		},
+		{
+			Name:      "run",
+			Usage:     "Run a request or endpoint, or several of them by name or pattern like 'get_*'",
+			ArgsUsage: "name [variable values...] [--data file] | pattern [name|pattern...] | --batch name|pattern...",
+			Flags: append(outputFlags(OUTPUT_RAW),
+				cli.BoolFlag{
+					Name:  "all",
+					Usage: "Run every request",
+				},
+				cli.BoolFlag{
+					Name:  "batch",
+					Usage: "Take every arg as a request name or pattern, not as variable values",
+				},
+				cli.IntFlag{
+					Name:  "parallel",
+					Value: 1,
+					Usage: "How many requests run at once when several are given",
+				},
+				cli.StringFlag{
+					Name:  "data",
+					Usage: "Run the request once per row of a csv or json file",
+				},
+				cli.BoolFlag{
+					Name:  "fail",
+					Usage: "Exit with 1 when a single request gets a 4xx or 5xx status",
+				},
+			),
+			Action: func(c *cli.Context) error {
+				output, err := newResponsePrinter(c)
+				if err != nil {
+					return cli.NewExitError(err.Error(), 1)
+				}
+				conf, err := loadConfiguration(c)
+				if err != nil {
+					return cli.NewExitError(err.Error(), 1)
+				}
+				executor, err := newExecutor(conf)
+				if err != nil {
+					return cli.NewExitError(err.Error(), 1)
+				}
+				executor.SetOutput(output)
+				names, batch, err := executor.SelectRequests(c.Args(), c.Bool("all"), c.Bool("batch"))
+				if err != nil {
+					return cli.NewExitError(err.Error(), 1)
+				}
+				if batch && c.IsSet("data") {
+					return cli.NewExitError("--data needs a single request", 1)
+				}
+				runner := NewBatchRunner(executor, output, os.Stderr, c.Int("parallel"))
+				failed := 0
+				failure := ""
 				requestName := c.Args().First()
-				return executor.RunRequest(requestName, c.Args().Tail())
+				if batch {
+					failed = runner.Run(names)
+				} else if dataset, err := executor.Dataset(requestName, c.String("data")); err != nil {
+					return cli.NewExitError(err.Error(), 1)
+				} else if dataset != nil {
+					failed = runner.RunData(requestName, dataset, c.Args().Tail())
+				} else {
+					response, err := executor.ExecuteRequest(requestName, c.Args().Tail())
+					output.Print(requestName, response, err)
+					if err != nil {
+						return cli.NewExitError(err.Error(), 1)
+					}
+					if c.Bool("fail") && response.StatusCode >= 400 {
+						failure = fmt.Sprintf("Request %v got status %v", requestName, response.StatusCode)
+					}
+				}
+				if err := conf.Session.Save(); err != nil {
+					return cli.NewExitError(err.Error(), 1)
+				}
+				if failure != "" {
+					return cli.NewExitError(failure, 1)
+				} else if failed == 1 {
+					return cli.NewExitError("1 request failed", 1)
+				} else if failed > 1 {
+					return cli.NewExitError(fmt.Sprintf("%v requests failed", failed), 1)
+				}
+				return nil
+			},
+		},
+		{
+			Name:      "bench",
+			Usage:     "Send a request many times with the native http client and report the latencies",
+			ArgsUsage: "name [variable values...]",
+			Flags: []cli.Flag{
+				cli.IntFlag{
+					Name:  "n",
+					Value: 100,
+					Usage: "Number of requests, 0 for no limit with a duration",
+				},
+				cli.IntFlag{
+					Name:  "c",
+					Value: 10,
+					Usage: "Number of requests sent at once",
+				},
+				cli.DurationFlag{
+					Name:  "duration",
+					Usage: "Stop after this time, e.g. 30s",
+				},
+				cli.StringFlag{
+					Name:  "rate",
+					Usage: "Requests sent per second, e.g. 100/s or 600/m",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				rate, err := ParseRate(c.String("rate"))
+				if err != nil {
+					return cli.NewExitError(err.Error(), 1)
+				}
+				conf, err := loadConfiguration(c)
+				if err != nil {
+					return err
+				}
+				executor, err := newExecutor(conf)
+				if err != nil {
+					return err
+				}
+				request, err := executor.ResolveRequest(c.Args().First(), c.Args().Tail())
+				if err != nil {
+					return cli.NewExitError(err.Error(), 1)
+				}
+				options := &BenchOptions{Requests: c.Int("n"), Concurrency: c.Int("c"), Duration: c.Duration("duration"), Rate: rate}
+				if options.Duration > 0 && !c.IsSet("n") {
+					options.Requests = 0
+				}
+				fmt.Printf("%v %v\n\n", request.Method, requestUrl(request))
+				report, err := Bench(request, options)
+				if err != nil {
+					return cli.NewExitError(err.Error(), 1)
+				}
+				report.Print(os.Stdout)
+				return nil
+			},
+		},
+		{
+			Name:  "flow",
+			Usage: "Run the steps of a flow, or list the flows when no name is given",
+			Action: func(c *cli.Context) error {
+				conf, err := loadConfiguration(c)
+				if err != nil {
+					return err
+				}
+				if !c.Args().Present() {
+					printer := &Printer{conf: conf, writer: os.Stdout, oneLine: oneLine}
+					printer.ShowFlows()
+					return nil
+				}
+				executor, err := newExecutor(conf)
+				if err != nil {
+					return err
+				}
+				if err := NewFlowRunner(executor, os.Stdout).Run(c.Args().First()); err != nil {
+					conf.Session.Save()
+					return cli.NewExitError(err.Error(), 1)
+				}
+				return conf.Session.Save()
+			},
+		},
+		{
+			Name:  "test",
+			Usage: "Run requests and check their expectations",
+			Action: func(c *cli.Context) error {
+				conf, err := loadConfiguration(c)
+				if err != nil {
+					return err
+				}
+				executor, err := newExecutor(conf)
+				if err != nil {
+					return err
+				}
+				failed, err := NewTester(executor, os.Stdout).Test(c.Args())
+				if err != nil {
+					return cli.NewExitError(err.Error(), 1)
+				}
+				if err := conf.Session.Save(); err != nil {
+					return err
+				}
+				if failed > 0 {
+					return cli.NewExitError("", 1)
+				}
+				return nil
+			},
+		},
+		{
+			Name:  "mock",
+			Usage: "Serve the endpoints with their mock responses or the snapshots of their requests",
+			Flags: []cli.Flag{
+				cli.IntFlag{
+					Name:  "port",
+					Value: 8080,
+					Usage: "Port to listen on",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				conf, err := loadConfiguration(c)
+				if err != nil {
+					return err
+				}
+				server, err := NewMockServer(conf, filepath.Join(directory, SNAPSHOT_DIRECTORY), os.Stdout)
+				if err != nil {
+					return cli.NewExitError(err.Error(), 1)
+				}
+				address := ":" + strconv.Itoa(c.Int("port"))
+				fmt.Printf("Mock server listening on %v\n", address)
+				if err := http.ListenAndServe(address, server); err != nil {
+					return cli.NewExitError(err.Error(), 1)
+				}
+				return nil
+			},
+		},
+		{
+			Name:  "record",
+			Usage: "Proxy the calls to the api, recording their responses for replay",
+			Flags: []cli.Flag{
+				cli.IntFlag{
+					Name:  "port",
+					Value: 9000,
+					Usage: "Port to listen on",
+				},
+				cli.StringFlag{
+					Name:  "target",
+					Usage: "Url of the api, by default the yaml url",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				conf, err := loadConfiguration(c)
+				if err != nil {
+					return err
+				}
+				targetUrl := c.String("target")
+				if targetUrl == "" {
+					targetUrl = conf.GlobalUrl
+				}
+				target, err := url.Parse(targetUrl)
+				if err != nil || target.Host == "" {
+					return cli.NewExitError(fmt.Sprintf("Invalid target '%v'", targetUrl), 1)
+				}
+				store, err := NewRecordingStore(filepath.Join(directory, RECORDINGS_FILE))
+				if err != nil {
+					return cli.NewExitError(err.Error(), 1)
+				}
+				proxy, err := NewRecordingProxy(conf, target, store, os.Stdout)
+				if err != nil {
+					return cli.NewExitError(err.Error(), 1)
+				}
+				address := ":" + strconv.Itoa(c.Int("port"))
+				fmt.Printf("Recording %v on %v\n", target, address)
+				if err := http.ListenAndServe(address, proxy); err != nil {
+					return cli.NewExitError(err.Error(), 1)
+				}
+				return nil
+			},
+		},
+		{
+			Name:  "replay",
+			Usage: "Serve the recorded responses",
+			Flags: []cli.Flag{
+				cli.IntFlag{
+					Name:  "port",
+					Value: 9000,
+					Usage: "Port to listen on",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				conf, err := loadConfiguration(c)
+				if err != nil {
+					return err
+				}
+				store, err := NewRecordingStore(filepath.Join(directory, RECORDINGS_FILE))
+				if err != nil {
+					return cli.NewExitError(err.Error(), 1)
+				}
+				server, err := NewReplayServer(conf, store, os.Stdout)
+				if err != nil {
+					return cli.NewExitError(err.Error(), 1)
+				}
+				address := ":" + strconv.Itoa(c.Int("port"))
+				fmt.Printf("Replaying on %v\n", address)
+				if err := http.ListenAndServe(address, server); err != nil {
+					return cli.NewExitError(err.Error(), 1)
+				}
+				return nil
+			},
+		},
+		{
+			Name:  "snapshot",
+			Usage: "Record the responses of requests and check them for drift",
+			Subcommands: []cli.Command{
+				{
+					Name:      "update",
+					Usage:     "Save the responses of the requests, all of them when none is given",
+					ArgsUsage: "[name...]",
+					Action: func(c *cli.Context) error {
+						conf, err := loadConfiguration(c)
+						if err != nil {
+							return err
+						}
+						executor, err := newExecutor(conf)
+						if err != nil {
+							return err
+						}
+						failed, err := NewSnapshotter(executor, os.Stdout, filepath.Join(directory, SNAPSHOT_DIRECTORY)).Update(c.Args())
+						if err != nil {
+							return cli.NewExitError(err.Error(), 1)
+						}
+						if err := conf.Session.Save(); err != nil {
+							return err
+						}
+						if failed > 0 {
+							return cli.NewExitError("", 1)
+						}
+						return nil
+					},
+				},
+				{
+					Name:      "check",
+					Usage:     "Compare the responses of the requests with their snapshots, all of them when none is given",
+					ArgsUsage: "[name...]",
+					Action: func(c *cli.Context) error {
+						conf, err := loadConfiguration(c)
+						if err != nil {
+							return err
+						}
+						executor, err := newExecutor(conf)
+						if err != nil {
+							return err
+						}
+						failed, err := NewSnapshotter(executor, os.Stdout, filepath.Join(directory, SNAPSHOT_DIRECTORY)).Check(c.Args())
+						if err != nil {
+							return cli.NewExitError(err.Error(), 1)
+						}
+						if err := conf.Session.Save(); err != nil {
+							return err
+						}
+						if failed > 0 {
+							return cli.NewExitError("", 1)
+						}
+						return nil
+					},
+				},
+			},
+		},
+		{
+			Name:      "diff",
+			Usage:     "Compare the responses of a request in two environments, or of two history executions",
+			ArgsUsage: "name --env a --env b | id id",
+			Flags: []cli.Flag{
+				cli.StringSliceFlag{
+					Name:  "env",
+					Usage: "Environment to run the request in, given twice",
+				},
+				cli.StringSliceFlag{
+					Name:  "ignore",
+					Usage: "Json path not compared, e.g. '$.updated_at' or 'items[*].id'. Can be repeated",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				environments := c.StringSlice("env")
+				ignore := c.StringSlice("ignore")
+				var left, right *Response
+				var leftName, rightName string
+				if len(environments) == 2 && len(c.Args()) >= 1 {
+					name := c.Args().First()
+					responses := make([]*Response, 2)
+					for i := range environments {
+						environment = environments[i]
+						conf, err := loadConfiguration(c)
+						if err != nil {
+							return cli.NewExitError(err.Error(), 2)
+						}
+						if request := conf.Requests[name]; request != nil {
+							ignore = append(ignore, request.Ignore...)
+						}
+						executor, err := newExecutor(conf)
+						if err != nil {
+							return cli.NewExitError(err.Error(), 2)
+						}
+						if responses[i], err = executor.ExecuteRequest(name, c.Args().Tail()); err != nil {
+							return cli.NewExitError(err.Error(), 2)
+						}
+					}
+					left, right = responses[0], responses[1]
+					leftName, rightName = name+" "+environments[0], name+" "+environments[1]
+				} else if len(environments) == 0 && len(c.Args()) == 2 {
+					history := NewHistoryStore(filepath.Join(directory, HISTORY_DIRECTORY), 0)
+					leftEntry, err := history.Get(c.Args().Get(0))
+					if err != nil {
+						return cli.NewExitError(err.Error(), 2)
+					}
+					rightEntry, err := history.Get(c.Args().Get(1))
+					if err != nil {
+						return cli.NewExitError(err.Error(), 2)
+					}
+					// the configuration is optional, only used for the ignore paths of the request
+					if conf, err := loadConfiguration(c); err == nil {
+						if request := conf.Requests[leftEntry.Name]; request != nil {
+							ignore = append(ignore, request.Ignore...)
+						}
+					}
+					left, right = leftEntry.AsResponse(), rightEntry.AsResponse()
+					leftName, rightName = leftEntry.Id+" "+leftEntry.Name, rightEntry.Id+" "+rightEntry.Name
+				} else {
+					return cli.NewExitError("Expected a request and two --env, or two history ids", 2)
+				}
+
+				differences := DiffResponses(left, right, ignore)
+				if len(differences) == 0 {
+					fmt.Println("No differences")
+					return nil
+				}
+				fmt.Printf("--- %v\n+++ %v\n", leftName, rightName)
+				for _, difference := range differences {
+					fmt.Println(difference)
+				}
+				return cli.NewExitError("", 1)
+			},
+		},
+		{
+			Name:  "session",
+			Usage: "List or clear the variables captured from the responses",
+			Subcommands: []cli.Command{
+				{
+					Name:  "list",
+					Usage: "List the captured variables",
+					Action: func(c *cli.Context) error {
+						session, err := NewFileVariableStore(filepath.Join(directory, SESSION_FILE))
+						if err != nil {
+							return cli.NewExitError(err.Error(), 1)
+						}
+						for _, name := range session.Names() {
+							value, _ := session.Get(name)
+							fmt.Printf("%v: %v\n", name, value)
+						}
+						return nil
+					},
+				},
+				{
+					Name:  "clear",
+					Usage: "Remove the captured variables",
+					Action: func(c *cli.Context) error {
+						file := filepath.Join(directory, SESSION_FILE)
+						session, err := NewFileVariableStore(file)
+						if err == nil {
+							err = session.Clear()
+						} else {
+							// a file that can't be read is removed all the same
+							err = os.Remove(file)
+						}
+						if err != nil {
+							return cli.NewExitError(err.Error(), 1)
+						}
+						return nil
+					},
+				},
+			},
+		},
+		{
+			Name:  "history",
+			Usage: "List, show and run again the past executions",
+			Subcommands: []cli.Command{
+				{
+					Name:      "list",
+					Usage:     "List the executions, only those of a request or endpoint when given",
+					ArgsUsage: "[name]",
+					Action: func(c *cli.Context) error {
+						history := NewHistoryStore(filepath.Join(directory, HISTORY_DIRECTORY), 0)
+						if err := ShowHistory(history, c.Args().First(), os.Stdout); err != nil {
+							return cli.NewExitError(err.Error(), 1)
+						}
+						return nil
+					},
+				},
+				{
+					Name:      "show",
+					Usage:     "Show an execution, the most recent one when no id is given",
+					ArgsUsage: "[id]",
+					Flags:     outputFlags(OUTPUT_PRETTY),
+					Action: func(c *cli.Context) error {
+						output, err := newResponsePrinter(c)
+						if err != nil {
+							return cli.NewExitError(err.Error(), 1)
+						}
+						entry, err := NewHistoryStore(filepath.Join(directory, HISTORY_DIRECTORY), 0).Get(c.Args().First())
+						if err != nil {
+							return cli.NewExitError(err.Error(), 1)
+						}
+						output.Print(entry.Name, entry.AsResponse(), nil)
+						return nil
+					},
+				},
+				{
+					Name:      "rerun",
+					Usage:     "Run again the request of an execution, the most recent one when no id is given",
+					ArgsUsage: "[id]",
+					Flags:     outputFlags(OUTPUT_RAW),
+					Action: func(c *cli.Context) error {
+						output, err := newResponsePrinter(c)
+						if err != nil {
+							return cli.NewExitError(err.Error(), 1)
+						}
+						entry, err := NewHistoryStore(filepath.Join(directory, HISTORY_DIRECTORY), 0).Get(c.Args().First())
+						if err != nil {
+							return cli.NewExitError(err.Error(), 1)
+						}
+						if environment == "" {
+							environment = entry.Environment
+						}
+						conf, err := loadConfiguration(c)
+						if err != nil {
+							return err
+						}
+						executor, err := newExecutor(conf)
+						if err != nil {
+							return err
+						}
+						response, err := executor.Rerun(entry)
+						output.Print(entry.Name, response, err)
+						if err != nil {
+							return cli.NewExitError(err.Error(), 1)
+						}
+						return nil
+					},
+				},
+			},
+		},
+		{
+			Name:  "import",
+			Usage: "Print the yaml of requests defined elsewhere",
+			Subcommands: []cli.Command{
+				{
+					Name:      "curl",
+					Usage:     "Import a curl command line, given as argument or from stdin",
+					ArgsUsage: "[curl command]",
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "name",
+							Usage: "Name of the endpoint, by default taken from the method and path",
+						},
+					},
+					Action: func(c *cli.Context) error {
+						args := []string(c.Args())
+						if len(args) <= 1 {
+							command := c.Args().First()
+							if len(args) == 0 {
+								source, err := ioutil.ReadAll(os.Stdin)
+								if err != nil {
+									return err
+								}
+								command = string(source)
+							}
+							var err error
+							if args, err = splitCommandLine(command); err != nil {
+								return cli.NewExitError(err.Error(), 1)
+							}
+						}
+						writer, err := ImportCurl(args, c.String("name"))
+						if err != nil {
+							return cli.NewExitError(err.Error(), 1)
+						}
+						return writer.Write(os.Stdout)
+					},
+				},
+				{
+					Name:      "openapi",
+					Usage:     "Import the operations of an OpenAPI 3 or Swagger 2 spec, in yaml or json",
+					ArgsUsage: "spec.yaml",
+					Flags: []cli.Flag{
+						cli.BoolFlag{
+							Name:  "examples",
+							Usage: "Also create requests from the parameter examples",
+						},
+					},
+					Action: func(c *cli.Context) error {
+						if !c.Args().Present() {
+							return cli.NewExitError("Missing spec file", 1)
+						}
+						source, err := ioutil.ReadFile(c.Args().First())
+						if err != nil {
+							return cli.NewExitError(err.Error(), 1)
+						}
+						writer, err := ImportOpenApi(source, c.Bool("examples"))
+						if err != nil {
+							return cli.NewExitError(err.Error(), 1)
+						}
+						return writer.Write(os.Stdout)
+					},
+				},
+				{
+					Name:      "postman",
+					Usage:     "Import the requests of a Postman collection",
+					ArgsUsage: "collection.json",
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "environment",
+							Usage: "Also import a Postman environment file as a gohit environment",
+						},
+					},
+					Action: func(c *cli.Context) error {
+						if !c.Args().Present() {
+							return cli.NewExitError("Missing collection file", 1)
+						}
+						source, err := ioutil.ReadFile(c.Args().First())
+						if err != nil {
+							return cli.NewExitError(err.Error(), 1)
+						}
+						var environmentSource []byte
+						if c.String("environment") != "" {
+							if environmentSource, err = ioutil.ReadFile(c.String("environment")); err != nil {
+								return cli.NewExitError(err.Error(), 1)
+							}
+						}
+						writer, err := ImportPostman(source, environmentSource)
+						if err != nil {
+							return cli.NewExitError(err.Error(), 1)
+						}
+						return writer.Write(os.Stdout)
+					},
+				},
+				{
+					Name:      "har",
+					Usage:     "Import the requests of a har file saved from the browser",
+					ArgsUsage: "file.har",
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "include",
+							Usage: "Only import the urls matching this regular expression",
+						},
+					},
+					Action: func(c *cli.Context) error {
+						if !c.Args().Present() {
+							return cli.NewExitError("Missing har file", 1)
+						}
+						source, err := ioutil.ReadFile(c.Args().First())
+						if err != nil {
+							return cli.NewExitError(err.Error(), 1)
+						}
+						var include *regexp.Regexp
+						if c.String("include") != "" {
+							if include, err = regexp.Compile(c.String("include")); err != nil {
+								return cli.NewExitError(err.Error(), 1)
+							}
+						}
+						writer, err := ImportHar(source, include)
+						if err != nil {
+							return cli.NewExitError(err.Error(), 1)
+						}
+						return writer.Write(os.Stdout)
+					},
+				},
+			},
+		},
+		{
+			Name:  "export",
+			Usage: "Print the endpoints in other formats",
+			Subcommands: []cli.Command{
+				{
+					Name:  "openapi",
+					Usage: "Export the endpoints as an OpenAPI 3 document",
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "title",
+							Value: "gohit",
+							Usage: "Title of the document",
+						},
+					},
+					Action: func(c *cli.Context) error {
+						conf, err := loadConfiguration(c)
+						if err != nil {
+							return err
+						}
+						skipped, err := ExportOpenApi(conf, c.String("title"), os.Stdout)
+						if err != nil {
+							return cli.NewExitError(err.Error(), 1)
+						}
+						for _, name := range skipped {
+							fmt.Fprintf(os.Stderr, "Endpoint %v not exported, same path and method as another endpoint\n", name)
+						}
+						return nil
+					},
+				},
+				{
+					Name:  "postman",
+					Usage: "Export the requests as a Postman collection, or an environment as a Postman environment",
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "name",
+							Value: "gohit",
+							Usage: "Name of the collection",
+						},
+						cli.StringFlag{
+							Name:  "environment",
+							Usage: "Export this environment instead of the collection",
+						},
+					},
+					Action: func(c *cli.Context) error {
+						conf, err := loadConfiguration(c)
+						if err != nil {
+							return err
+						}
+						if c.String("environment") != "" {
+							err = ExportPostmanEnvironment(conf, c.String("environment"), os.Stdout)
+						} else {
+							err = ExportPostman(conf, c.String("name"), os.Stdout)
+						}
+						if err != nil {
+							return cli.NewExitError(err.Error(), 1)
+						}
+						return nil
+					},
+				},
 			},
 		},
 	}
@@ -143,16 +958,24 @@ func (request *Request) GetName() string {
 	return request.Name
 }
 
-func (endpoint *Endpoint) GetOptions() map[string]bool {
+func (endpoint *Endpoint) GetOptions() []string {
 	return endpoint.Options
 }
 
-func (request *Request) GetOptions() map[string]bool {
+func (request *Request) GetOptions() []string {
 	return request.Options
 }
 
+func (endpoint *Endpoint) GetBody() string {
+	return endpoint.Body
+}
+
+func (request *Request) GetBody() string {
+	return request.Body
+}
+
 func (request *Request) String() string {
-	return fmt.Sprintf("%v %v %v %v QueryRaw=%v QueryList=%v Headers=%v Options=%v Param=%v", request.Name,
+	return fmt.Sprintf("%v %v %v %v QueryRaw=%v QueryList=%v Headers=%v Options=%v Body=%v Param=%v", request.Name,
 		request.Method,
 		request.Url,
 		request.Path,
@@ -160,12 +983,13 @@ func (request *Request) String() string {
 		request.QueryList,
 		len(request.Headers),
 		len(request.Options),
+		len(request.Body),
 		len(request.Parameters),
 	)
 }
 
 func (endpoint *Endpoint) String() string {
-	return fmt.Sprintf("%v %v %v %v QueryRaw=%v QueryList=%v Headers=%v Options=%v", endpoint.Name,
+	return fmt.Sprintf("%v %v %v %v QueryRaw=%v QueryList=%v Headers=%v Options=%v Body=%v", endpoint.Name,
 		endpoint.Method,
 		endpoint.Url,
 		endpoint.Path,
@@ -173,5 +997,6 @@ func (endpoint *Endpoint) String() string {
 		endpoint.QueryList,
 		len(endpoint.Headers),
 		len(endpoint.Options),
+		len(endpoint.Body),
 	)
 }
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// expandMatrix returns the parameters of every combination of the matrix values of a
+// request, by name, or just the request when it has no matrix. The combinations are named
+// like 'request[format=xml,version=2]', with the variables sorted. The variables set with
+// --var aren't expanded, as they override the matrix values anyway.
+func (conf *Configuration) expandMatrix(name string, value interface{}) (map[string]interface{}, error) {
+	parameters, ok := value.(map[interface{}]interface{})
+	if !ok || parameters[MATRIX] == nil {
+		return map[string]interface{}{name: value}, nil
+	}
+	matrix, ok := parameters[MATRIX].(map[interface{}]interface{})
+	if !ok || len(matrix) == 0 {
+		return nil, errors.New(fmt.Sprintf("Request %v has an invalid matrix: it should map variables to lists of values", name))
+	}
+
+	variables := make([]string, 0, len(matrix))
+	for variable := range matrix {
+		variableName, ok := variable.(string)
+		if !ok || requestAttributes[variableName] {
+			return nil, errors.New(fmt.Sprintf("Request %v has an invalid matrix: '%v' isn't a variable name", name, variable))
+		}
+		if _, ok := conf.Variables[variableName]; !ok {
+			variables = append(variables, variableName)
+		}
+	}
+	sort.Strings(variables)
+	for _, variable := range variables {
+		values, ok := matrix[variable].([]interface{})
+		if !ok || len(values) == 0 {
+			return nil, errors.New(fmt.Sprintf("Request %v has an invalid matrix: '%v' should be a list of values", name, variable))
+		}
+		for _, v := range values {
+			switch v.(type) {
+			case string, int, float64, bool:
+			default:
+				return nil, errors.New(fmt.Sprintf("Request %v has an invalid matrix: '%v' has a value that isn't a string, number or boolean", name, variable))
+			}
+		}
+	}
+
+	if len(variables) == 0 {
+		request := make(map[interface{}]interface{}, len(parameters))
+		for k, v := range parameters {
+			if k != MATRIX {
+				request[k] = v
+			}
+		}
+		return map[string]interface{}{name: request}, nil
+	}
+
+	combinations := []map[interface{}]interface{}{{}}
+	for _, variable := range variables {
+		var expanded []map[interface{}]interface{}
+		for _, combination := range combinations {
+			for _, v := range matrix[variable].([]interface{}) {
+				next := make(map[interface{}]interface{}, len(combination)+1)
+				for k, value := range combination {
+					next[k] = value
+				}
+				next[variable] = v
+				expanded = append(expanded, next)
+			}
+		}
+		combinations = expanded
+	}
+
+	requests := make(map[string]interface{}, len(combinations))
+	for _, combination := range combinations {
+		values := make([]string, len(variables))
+		for i, variable := range variables {
+			values[i] = variable + "=" + conf.getReplacement(combination[variable])
+		}
+		for k, v := range parameters {
+			if k != MATRIX {
+				if _, ok := combination[k]; !ok {
+					combination[k] = v
+				}
+			}
+		}
+		requests[name+"["+strings.Join(values, ",")+"]"] = combination
+	}
+	return requests, nil
+}
+
+// MatrixRequests returns the sorted names of the requests expanded from the matrix of a request.
+func (conf *Configuration) MatrixRequests(name string) []string {
+	var names []string
+	for requestName := range conf.Requests {
+		if strings.HasPrefix(requestName, name+"[") && strings.HasSuffix(requestName, "]") {
+			names = append(names, requestName)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const matrixConfiguration = `
+url: local
+
+endpoints:
+  get_report:
+    path: /{version}/report
+    headers:
+      - 'Accept: application/{format}'
+
+requests:
+  report:
+    endpoint: get_report
+    version: v1
+    matrix:
+      version: [v1, 2]
+      format:
+        - json
+        - xml
+  other:
+    endpoint: get_report
+    version: v3
+    format: csv
+`
+
+func TestMatrixRequests(t *testing.T) {
+	reader := &MockReader{configurations: make(map[string][]byte)}
+	reader.configurations["test"] = []byte(matrixConfiguration)
+	conf, err := NewConfiguration(reader)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	names := conf.MatrixRequests("report")
+	expected := []string{"report[format=json,version=2]", "report[format=json,version=v1]", "report[format=xml,version=2]", "report[format=xml,version=v1]"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("Unexpected matrix requests %v", names)
+	}
+	if conf.Requests["report"] != nil || len(conf.Requests) != 5 {
+		t.Errorf("Should have replaced the request by its combinations but got %v requests", len(conf.Requests))
+	}
+	request := conf.Requests["report[format=xml,version=2]"]
+	if request.Path != "/2/report" || request.Headers[0] != "Accept: application/xml" || request.Parameters[MATRIX] != nil {
+		t.Errorf("Unexpected request %v %v %v", request.Path, request.Headers, request.Parameters)
+	}
+	if names := conf.MatrixRequests("other"); len(names) != 0 {
+		t.Errorf("Should not have matrix requests but got %v", names)
+	}
+}
+
+func TestMatrixRequestsWithVariables(t *testing.T) {
+	reader := &MockReader{configurations: make(map[string][]byte)}
+	reader.configurations["test"] = []byte(matrixConfiguration)
+	conf, err := NewConfiguration(reader)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := conf.SetVariables(map[string]interface{}{"version": "3"}); err != nil {
+		t.Error(err)
+		return
+	}
+	names := conf.MatrixRequests("report")
+	if !reflect.DeepEqual(names, []string{"report[format=json]", "report[format=xml]"}) {
+		t.Errorf("Should not have expanded the variable set with --var but got %v", names)
+	}
+	if request := conf.Requests["report[format=xml]"]; request.Path != "/3/report" || request.Headers[0] != "Accept: application/xml" {
+		t.Errorf("Unexpected request %v %v", request.Path, request.Headers)
+	}
+
+	if err := conf.SetVariables(map[string]interface{}{"version": "3", "format": "csv"}); err != nil {
+		t.Error(err)
+		return
+	}
+	request := conf.Requests["report"]
+	if len(conf.MatrixRequests("report")) != 0 || request == nil || request.Path != "/3/report" || request.Headers[0] != "Accept: application/csv" {
+		t.Errorf("Should have a single request when every matrix variable is set but got %v", conf.MatrixRequests("report"))
+	}
+}
+
+func TestMatrixRequestsSelected(t *testing.T) {
+	reader := &MockReader{configurations: make(map[string][]byte)}
+	reader.configurations["test"] = []byte(matrixConfiguration)
+	conf, err := NewConfiguration(reader)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	executor := NewExecutor(conf, &MockResponseRunner{}, &MockVariableReader{})
+
+	for _, selection := range []struct {
+		args  []string
+		names []string
+		batch bool
+	}{
+		{[]string{"report[format=xml,version=v1]"}, []string{"report[format=xml,version=v1]"}, false},
+		{[]string{"report"}, conf.MatrixRequests("report"), true},
+		{[]string{"*=json,*", "other"}, []string{"report[format=json,version=2]", "report[format=json,version=v1]", "other"}, true},
+		{[]string{"other", "*=json,*"}, []string{"other"}, false},
+	} {
+		names, batch, err := executor.SelectRequests(selection.args, false, false)
+		if err != nil || batch != selection.batch || !reflect.DeepEqual(names, selection.names) {
+			t.Errorf("Unexpected selection for %v: %v %v %v", selection.args, names, batch, err)
+		}
+	}
+
+	var b bytes.Buffer
+	printer := &Printer{conf: conf, writer: &b}
+	printer.ShowRequestOrEndpoint("report")
+	if strings.Count(b.String(), "Endpoint report[") != 4 || !strings.Contains(b.String(), "Endpoint report[format=json,version=2]:\ncurl 'local/2/report'") {
+		t.Errorf("Should have shown every combination but got %v", b.String())
+	}
+}
+
+func TestInvalidMatrix(t *testing.T) {
+	for matrix, message := range map[string]string{
+		"matrix: [1, 2]":                   "Request report has an invalid matrix: it should map variables to lists of values",
+		"matrix:\n      version: 1":        "Request report has an invalid matrix: 'version' should be a list of values",
+		"matrix:\n      version: []":       "Request report has an invalid matrix: 'version' should be a list of values",
+		"matrix:\n      version: [{a: 1}]": "Request report has an invalid matrix: 'version' has a value that isn't a string, number or boolean",
+		"matrix:\n      1: [a]":            "Request report has an invalid matrix: '1' isn't a variable name",
+		"matrix:\n      body: [a]":         "Request report has an invalid matrix: 'body' isn't a variable name",
+	} {
+		reader := &MockReader{configurations: make(map[string][]byte)}
+		reader.configurations["test"] = []byte(`
+url: local
+
+endpoints:
+  get_report:
+    path: /{version}/report
+
+requests:
+  report:
+    endpoint: get_report
+    ` + matrix + `
+`)
+		if _, err := NewConfiguration(reader); err == nil || err.Error() != message {
+			t.Errorf("Should have thrown '%v' but got %v", message, err)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MockResponse is the canned response of an endpoint served by 'gohit mock'.
+type MockResponse struct {
+	Status  int
+	Headers []string
+	Body    string
+	Delay   time.Duration
+}
+
+const (
+	MOCK = "mock"
+
+	MOCK_STATUS  = "status"
+	MOCK_HEADERS = "headers"
+	MOCK_BODY    = "body"
+	MOCK_DELAY   = "delay"
+)
+
+func (conf *Configuration) readMockResponse(value interface{}) (*MockResponse, error) {
+	definition, ok := value.(map[interface{}]interface{})
+	if !ok {
+		return nil, errors.New("mock must be a map")
+	}
+	mock := &MockResponse{Status: http.StatusOK}
+	for key, value := range definition {
+		var err error
+		switch key {
+		case MOCK_STATUS:
+			status, ok := value.(int)
+			if !ok {
+				return nil, errors.New(fmt.Sprintf("Invalid status '%v'", value))
+			}
+			mock.Status = status
+		case MOCK_HEADERS:
+			headers, ok := value.([]interface{})
+			if !ok {
+				return nil, errors.New("headers must be a list")
+			}
+			mock.Headers = asStrings(headers)
+		case MOCK_BODY:
+			if mock.Body, err = conf.readBody(value); err != nil {
+				return nil, err
+			}
+		case MOCK_DELAY:
+			if mock.Delay, err = readDuration(value); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, errors.New(fmt.Sprintf("Invalid mock attribute '%v'", key))
+		}
+	}
+	return mock, nil
+}
+
+// MockServer answers the calls to the endpoints with their mock response or, when they
+// have none, with the snapshot of one of their requests.
+type MockServer struct {
+	mutex  sync.Mutex
+	routes []*mockRoute
+	logger io.Writer
+}
+
+type mockRoute struct {
+	endpoint  *Endpoint
+	pattern   *regexp.Regexp
+	variables []string
+	// literal is the length of the path without variables, routes with a longer one are matched first
+	literal   int
+	snapshots []*mockSnapshot
+}
+
+type mockSnapshot struct {
+	request  string
+	path     string
+	snapshot *snapshot
+}
+
+// NewMockServer creates the routes of every endpoint, loading the snapshots of their requests.
+func NewMockServer(conf *Configuration, snapshotDirectory string, logger io.Writer) (*MockServer, error) {
+	routes, err := newMockRoutes(conf)
+	if err != nil {
+		return nil, err
+	}
+	server := &MockServer{routes: routes, logger: logger}
+
+	requestNames := make([]string, 0, len(conf.Requests))
+	for name := range conf.Requests {
+		requestNames = append(requestNames, name)
+	}
+	sort.Strings(requestNames)
+	for _, name := range requestNames {
+		saved, err := readSnapshot(snapshotDirectory, name)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		request := conf.Requests[name]
+		for _, route := range server.routes {
+			if route.endpoint.Name == fmt.Sprint(request.Parameters[ENDPOINT]) {
+				route.snapshots = append(route.snapshots, &mockSnapshot{request: name, path: request.Path, snapshot: saved})
+			}
+		}
+	}
+	return server, nil
+}
+
+// newMockRoutes returns the routes of every endpoint, the most specific ones first.
+func newMockRoutes(conf *Configuration) ([]*mockRoute, error) {
+	var routes []*mockRoute
+	for _, endpoint := range conf.Endpoints {
+		route, err := newMockRoute(endpoint)
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, route)
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].literal != routes[j].literal {
+			return routes[i].literal > routes[j].literal
+		}
+		return routes[i].endpoint.Name < routes[j].endpoint.Name
+	})
+	return routes, nil
+}
+
+// matchMockRoute returns the route of a call and the values of its path variables.
+func matchMockRoute(routes []*mockRoute, method string, path string) (*mockRoute, []string) {
+	for _, route := range routes {
+		if !strings.EqualFold(route.endpoint.Method, method) {
+			continue
+		}
+		if values := route.pattern.FindStringSubmatch(path); values != nil {
+			return route, values[1:]
+		}
+	}
+	return nil, nil
+}
+
+// newMockRoute matches the endpoint path, each {variable} matching a path segment or part of it.
+func newMockRoute(endpoint *Endpoint) (*mockRoute, error) {
+	route := &mockRoute{endpoint: endpoint}
+	expression := "^"
+	last := 0
+	for _, match := range variablePattern.FindAllStringIndex(endpoint.Path, -1) {
+		expression += regexp.QuoteMeta(endpoint.Path[last:match[0]]) + "([^/]+)"
+		route.variables = append(route.variables, endpoint.Path[match[0]:match[1]])
+		route.literal += match[0] - last
+		last = match[1]
+	}
+	expression += regexp.QuoteMeta(endpoint.Path[last:]) + "/?$"
+	route.literal += len(endpoint.Path) - last
+	pattern, err := regexp.Compile(expression)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Endpoint '%v' path can't be mocked: %v", endpoint.Name, err))
+	}
+	route.pattern = pattern
+	return route, nil
+}
+
+func (server *MockServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	route, values := matchMockRoute(server.routes, r.Method, r.URL.Path)
+	if route == nil {
+		server.log("%v %v -> UNMATCHED", r.Method, r.URL.RequestURI())
+		http.Error(w, fmt.Sprintf("No endpoint matches %v %v", r.Method, r.URL.Path), http.StatusNotFound)
+	} else if mock := route.endpoint.Mock; mock != nil {
+		server.serveMock(w, r, route, mock, values)
+	} else if saved := route.snapshot(r.URL.Path); saved != nil {
+		server.serveSnapshot(w, r, route, saved)
+	} else {
+		server.log("%v %v -> %v %v, no mock or snapshot", r.Method, r.URL.RequestURI(), route.endpoint.Name, http.StatusNotImplemented)
+		http.Error(w, fmt.Sprintf("Endpoint %v has no mock response or snapshot", route.endpoint.Name), http.StatusNotImplemented)
+	}
+}
+
+// serveMock replaces the endpoint path variables in the mock headers and body with the called path values.
+func (server *MockServer) serveMock(w http.ResponseWriter, r *http.Request, route *mockRoute, mock *MockResponse, values []string) {
+	replacer := make([]string, 0, len(values)*2)
+	for i := range values {
+		replacer = append(replacer, route.variables[i], values[i])
+	}
+	replace := strings.NewReplacer(replacer...).Replace
+	for _, header := range mock.Headers {
+		if i := strings.Index(header, ":"); i > 0 {
+			w.Header().Add(strings.TrimSpace(header[:i]), replace(strings.TrimSpace(header[i+1:])))
+		}
+	}
+	time.Sleep(mock.Delay)
+	w.WriteHeader(mock.Status)
+	io.WriteString(w, replace(mock.Body))
+	server.log("%v %v -> %v %v", r.Method, r.URL.RequestURI(), route.endpoint.Name, mock.Status)
+}
+
+func (server *MockServer) serveSnapshot(w http.ResponseWriter, r *http.Request, route *mockRoute, saved *mockSnapshot) {
+	response := saved.snapshot.asResponse()
+	for name, values := range response.Headers {
+		w.Header()[name] = values
+	}
+	w.WriteHeader(response.StatusCode)
+	w.Write(response.Body)
+	server.log("%v %v -> %v %v, snapshot of %v", r.Method, r.URL.RequestURI(), route.endpoint.Name, response.StatusCode, saved.request)
+}
+
+// snapshot prefers the snapshot of a request with the same path as the call.
+func (route *mockRoute) snapshot(path string) *mockSnapshot {
+	for _, saved := range route.snapshots {
+		if saved.path == path {
+			return saved
+		}
+	}
+	if len(route.snapshots) > 0 {
+		return route.snapshots[0]
+	}
+	return nil
+}
+
+func (server *MockServer) log(format string, args ...interface{}) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	fmt.Fprintf(server.logger, time.Now().Format("15:04:05")+" "+format+"\n", args...)
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+const mockConfiguration = `
+url: local
+
+endpoints:
+  get_user:
+    path: /users/{id}
+    mock:
+      headers:
+        - 'Content-Type: application/json'
+        - 'Location: /users/{id}'
+      body:
+        id: '{id}'
+  get_me:
+    path: /users/me
+    mock:
+      status: 401
+      body: unauthorized
+  create_user:
+    path: /users
+    method: POST
+  get_repo:
+    path: /repos/{owner}/{repo}.json
+
+requests:
+  fabio_repo:
+    endpoint: get_repo
+    owner: fabio
+    repo: sconsify
+  other_repo:
+    endpoint: get_repo
+    owner: other
+    repo: other
+`
+
+func TestMockServer(t *testing.T) {
+	reader := &MockReader{configurations: make(map[string][]byte)}
+	reader.configurations["test"] = []byte(mockConfiguration)
+	conf, err := NewConfiguration(reader)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	directory, _ := ioutil.TempDir("", "gohit")
+	defer os.RemoveAll(directory)
+	snapshotter := &Snapshotter{directory: directory}
+	snapshotter.save("fabio_repo", &snapshot{Status: 200, ContentType: "application/json", Json: map[string]interface{}{"name": "sconsify"}})
+	snapshotter.save("other_repo", &snapshot{Status: 404, Text: "not found"})
+
+	var log bytes.Buffer
+	mock, err := NewMockServer(conf, directory, &log)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	server := httptest.NewServer(mock)
+	defer server.Close()
+
+	for _, call := range []struct {
+		method string
+		path   string
+		status int
+		body   string
+		header string
+	}{
+		{"GET", "/users/42", 200, `{"id":"42"}`, "/users/42"},
+		{"GET", "/users/me", 401, "unauthorized", ""},
+		{"GET", "/repos/fabio/sconsify.json", 200, `{"name":"sconsify"}`, ""},
+		{"GET", "/repos/other/other.json", 404, "not found", ""},
+		{"GET", "/repos/any/any.json", 200, `{"name":"sconsify"}`, ""},
+		{"POST", "/users", 501, "Endpoint create_user has no mock response or snapshot\n", ""},
+		{"DELETE", "/users/42", 404, "No endpoint matches DELETE /users/42\n", ""},
+	} {
+		request, _ := http.NewRequest(call.method, server.URL+call.path, nil)
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		body, _ := ioutil.ReadAll(response.Body)
+		response.Body.Close()
+		if response.StatusCode != call.status || string(body) != call.body || response.Header.Get("Location") != call.header {
+			t.Errorf("Unexpected response to %v %v: %v %v %v", call.method, call.path, response.StatusCode, string(body), response.Header)
+		}
+	}
+
+	if !strings.Contains(log.String(), "GET /repos/fabio/sconsify.json -> get_repo 200, snapshot of fabio_repo") ||
+		!strings.Contains(log.String(), "DELETE /users/42 -> UNMATCHED") {
+		t.Errorf("Unexpected log %v", log.String())
+	}
+}
+
+func TestInvalidMock(t *testing.T) {
+	reader := &MockReader{configurations: make(map[string][]byte)}
+	reader.configurations["test"] = []byte(
+		`
+url: local
+
+endpoints:
+  test:
+    path: /test
+    mock:
+      code: 200
+`)
+
+	if _, err := NewConfiguration(reader); err == nil || err.Error() != "Endpoint 'test' has an invalid mock: Invalid mock attribute 'code'" {
+		t.Error("Should have thrown an invalid mock error but got ", err)
+	}
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// headers described by other parts of an OpenAPI document, not exported as parameters.
+var openApiIgnoredHeaders = map[string]bool{"accept": true, "content-type": true, "authorization": true}
+
+// ExportOpenApi writes the endpoints as an OpenAPI 3 document. Endpoints with the same
+// path and method as an earlier one, by name, are left out and returned.
+func ExportOpenApi(conf *Configuration, title string, out io.Writer) ([]string, error) {
+	names := make([]string, 0, len(conf.Endpoints))
+	for name := range conf.Endpoints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	operations := make(map[string]map[string]yaml.MapSlice)
+	var skipped []string
+	for _, name := range names {
+		endpoint := conf.Endpoints[name]
+		method := strings.ToLower(endpoint.Method)
+		if operations[endpoint.Path] == nil {
+			operations[endpoint.Path] = make(map[string]yaml.MapSlice)
+		}
+		if operations[endpoint.Path][method] != nil {
+			skipped = append(skipped, name)
+			continue
+		}
+		operations[endpoint.Path][method] = openApiOperation(conf, endpoint)
+	}
+
+	paths := make([]string, 0, len(operations))
+	for path := range operations {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	pathItems := yaml.MapSlice{}
+	for _, path := range paths {
+		pathItem := yaml.MapSlice{}
+		for _, method := range openApiMethods {
+			if operation := operations[path][method]; operation != nil {
+				pathItem = append(pathItem, yaml.MapItem{Key: method, Value: operation})
+			}
+		}
+		pathItems = append(pathItems, yaml.MapItem{Key: path, Value: pathItem})
+	}
+
+	document := yaml.MapSlice{
+		{Key: "openapi", Value: "3.0.3"},
+		{Key: "info", Value: yaml.MapSlice{{Key: "title", Value: title}, {Key: "version", Value: "1.0.0"}}},
+	}
+	if conf.GlobalUrl != "" {
+		document = append(document, yaml.MapItem{Key: "servers", Value: []yaml.MapSlice{openApiServer(conf, conf.GlobalUrl)}})
+	}
+	document = append(document, yaml.MapItem{Key: "paths", Value: pathItems})
+
+	source, err := yaml.Marshal(document)
+	if err != nil {
+		return nil, err
+	}
+	_, err = out.Write(source)
+	return skipped, err
+}
+
+// openApiServer describes a url, its {variables} become server variables defaulting to their global value.
+func openApiServer(conf *Configuration, url string) yaml.MapSlice {
+	server := yaml.MapSlice{{Key: URL, Value: url}}
+	variables := yaml.MapSlice{}
+	for _, placeholder := range variablePattern.FindAllString(url, -1) {
+		name := strings.Trim(placeholder, "{}")
+		value := name
+		if global, ok := conf.GlobalVariables[name]; ok {
+			value = conf.getReplacement(global)
+		}
+		variables = append(variables, yaml.MapItem{Key: name, Value: yaml.MapSlice{{Key: "default", Value: value}}})
+	}
+	if len(variables) > 0 {
+		server = append(server, yaml.MapItem{Key: "variables", Value: variables})
+	}
+	return server
+}
+
+func openApiOperation(conf *Configuration, endpoint *Endpoint) yaml.MapSlice {
+	operation := yaml.MapSlice{{Key: "operationId", Value: endpoint.Name}}
+	if endpoint.Url != conf.GlobalUrl {
+		operation = append(operation, yaml.MapItem{Key: "servers", Value: []yaml.MapSlice{openApiServer(conf, endpoint.Url)}})
+	}
+
+	// a parameter is unique by name and location, the first one is kept
+	parameters := []yaml.MapSlice{}
+	seen := make(map[string]bool)
+	addParameter := func(name string, in string, value string) {
+		key := in + ":" + name
+		if in == "header" {
+			key = in + ":" + strings.ToLower(name)
+		}
+		if !seen[key] {
+			seen[key] = true
+			parameters = append(parameters, openApiParameter(name, in, value))
+		}
+	}
+	for _, placeholder := range variablePattern.FindAllString(endpoint.Path, -1) {
+		addParameter(strings.Trim(placeholder, "{}"), "path", "")
+	}
+	for _, query := range endpointQuery(endpoint) {
+		addParameter(query[0], "query", query[1])
+	}
+	for _, header := range endpoint.Headers {
+		if openApiIgnoredHeaders[headerName(header)] || !strings.Contains(header, ":") {
+			continue
+		}
+		parts := strings.SplitN(strings.TrimPrefix(header, "+"), ":", 2)
+		value := strings.TrimSpace(parts[1])
+		if headerName(header) != "cookie" {
+			addParameter(strings.TrimSpace(parts[0]), "header", value)
+			continue
+		}
+		for _, cookie := range strings.Split(value, ";") {
+			if pair := strings.SplitN(strings.TrimSpace(cookie), "=", 2); len(pair) == 2 && pair[0] != "" {
+				addParameter(pair[0], "cookie", pair[1])
+			}
+		}
+	}
+	if len(parameters) > 0 {
+		operation = append(operation, yaml.MapItem{Key: "parameters", Value: parameters})
+	}
+
+	if endpoint.Body != "" {
+		contentType, example := openApiBody(endpoint)
+		media := yaml.MapSlice{{Key: contentType, Value: yaml.MapSlice{{Key: "example", Value: example}}}}
+		operation = append(operation, yaml.MapItem{Key: "requestBody", Value: yaml.MapSlice{{Key: "content", Value: media}}})
+	}
+
+	operation = append(operation, yaml.MapItem{Key: "responses", Value: yaml.MapSlice{
+		{Key: "default", Value: yaml.MapSlice{{Key: "description", Value: "Response of " + endpoint.Name}}},
+	}})
+	return operation
+}
+
+// openApiParameter uses value as example unless it's a placeholder.
+func openApiParameter(name string, in string, value string) yaml.MapSlice {
+	parameter := yaml.MapSlice{{Key: "name", Value: name}, {Key: "in", Value: in}}
+	if in == "path" {
+		parameter = append(parameter, yaml.MapItem{Key: "required", Value: true})
+	}
+	schema := yaml.MapSlice{{Key: "type", Value: "string"}}
+	if value != "" && !variablePattern.MatchString(value) {
+		schema = append(schema, yaml.MapItem{Key: "example", Value: value})
+	}
+	return append(parameter, yaml.MapItem{Key: "schema", Value: schema})
+}
+
+// endpointQuery returns the name and value of each query parameter, from either query format.
+func endpointQuery(endpoint *Endpoint) [][2]string {
+	var query [][2]string
+	for _, key := range endpoint.QueryListKeys {
+		query = append(query, [2]string{key, endpoint.QueryList[key]})
+	}
+	if endpoint.QueryRaw != "" {
+		for _, pair := range strings.Split(endpoint.QueryRaw, "&") {
+			parts := strings.SplitN(pair, "=", 2)
+			if len(parts) == 1 {
+				parts = append(parts, "")
+			}
+			query = append(query, [2]string{parts[0], parts[1]})
+		}
+	}
+	return query
+}
+
+// openApiBody returns the content type of the body, from its header or guessed, and the body as example.
+func openApiBody(endpoint *Endpoint) (string, interface{}) {
+	contentType := ""
+	for _, header := range endpoint.Headers {
+		if headerName(header) == "content-type" {
+			contentType = strings.TrimSpace(strings.SplitN(header, ":", 2)[1])
+		}
+	}
+	var asJson interface{}
+	isJson := json.Unmarshal([]byte(endpoint.Body), &asJson) == nil
+	if contentType == "" {
+		// same as curl --data-binary
+		contentType = "application/x-www-form-urlencoded"
+		if isJson {
+			contentType = "application/json"
+		}
+	}
+	if isJson && strings.Contains(contentType, "json") {
+		return contentType, asJson
+	}
+	return contentType, endpoint.Body
+}
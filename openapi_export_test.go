@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExportOpenApi(t *testing.T) {
+	conf, _ := NewConfiguration(NewSilentConfigurationReader("_resources/valid", "api-requests.yaml"))
+
+	var b bytes.Buffer
+	skipped, err := ExportOpenApi(conf, "test", &b)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(skipped) > 0 {
+		t.Errorf("Unexpected skipped endpoints %v", skipped)
+	}
+
+	writer, err := ImportOpenApi(b.Bytes(), false)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if writer.Url != "https://localhost" {
+		t.Errorf("Unexpected server %v", writer.Url)
+	}
+
+	endpoints := make(map[string]*Endpoint)
+	for _, endpoint := range writer.Endpoints {
+		endpoints[endpoint.Name] = endpoint
+	}
+	if len(endpoints) != len(conf.Endpoints) {
+		t.Errorf("Expected %v endpoints, got %v", len(conf.Endpoints), len(endpoints))
+	}
+	for name, original := range conf.Endpoints {
+		endpoint := endpoints[name]
+		if endpoint == nil || endpoint.Path != original.Path || endpoint.Method != original.Method {
+			t.Errorf("Endpoint %v not exported correctly %v", name, endpoint)
+		}
+	}
+	if !reflect.DeepEqual(endpoints["endpoint4"].QueryListKeys, []string{"name", "date", "version"}) {
+		t.Errorf("Unexpected query %v", endpoints["endpoint4"].QueryListKeys)
+	}
+}
+
+func TestExportOpenApiDetails(t *testing.T) {
+	reader := &MockReader{configurations: make(map[string][]byte)}
+	reader.configurations["test"] = []byte(
+		`
+url: https://{env}.localhost
+
+variables:
+  env: dev
+
+endpoints:
+  create:
+    path: /items
+    method: POST
+    headers:
+      - 'Content-Type: application/json'
+      - 'X-Tenant: {tenant}'
+    body:
+      name: gohit
+  create_again:
+    path: /items
+    method: POST
+  external:
+    url: http://other
+    path: /status
+`)
+	conf, err := NewConfiguration(reader)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	var b bytes.Buffer
+	skipped, _ := ExportOpenApi(conf, "test", &b)
+	if !reflect.DeepEqual(skipped, []string{"create_again"}) {
+		t.Errorf("Unexpected skipped endpoints %v", skipped)
+	}
+
+	document := b.String()
+	for _, expected := range []string{
+		"- url: https://{env}.localhost\n  variables:\n    env:\n      default: dev\n",
+		"      - name: X-Tenant\n        in: header\n        schema:\n          type: string\n      requestBody:",
+		"          application/json:\n            example:\n              name: gohit\n",
+		"      operationId: external\n      servers:\n      - url: http://other\n",
+	} {
+		if !strings.Contains(document, expected) {
+			t.Errorf("Expected %q in\n%v", expected, document)
+		}
+	}
+}
+
+func TestExportOpenApiUniqueParameters(t *testing.T) {
+	reader := &MockReader{configurations: make(map[string][]byte)}
+	reader.configurations["test"] = []byte(
+		`
+url: https://localhost
+
+endpoints:
+  get_item:
+    path: /items/{id}/copies/{id}
+    headers:
+      - 'Cookie: a=1'
+      - '+Cookie: b={session}; a=2'
+      - 'X-Trace: 1'
+      - '+x-trace: 2'
+`)
+	conf, err := NewConfiguration(reader)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	var b bytes.Buffer
+	if _, err := ExportOpenApi(conf, "test", &b); err != nil {
+		t.Error(err)
+		return
+	}
+	document := b.String()
+	for expected, count := range map[string]int{
+		"- name: id\n        in: path\n":        1,
+		"- name: X-Trace\n        in: header\n": 1,
+		"- name: a\n        in: cookie\n        schema:\n          type: string\n          example: \"1\"\n": 1,
+		"- name: b\n        in: cookie\n": 1,
+		"in: header":                      1,
+		"name: Cookie":                    0,
+	} {
+		if strings.Count(document, expected) != count {
+			t.Errorf("Expected %q %v times in\n%v", expected, count, document)
+		}
+	}
+}
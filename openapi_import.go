@@ -0,0 +1,281 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v2"
+)
+
+// openApiMethods in the order the operations of a path are imported.
+var openApiMethods = []string{"get", "post", "put", "patch", "delete", "head", "options"}
+
+var nonVariableCharacters = regexp.MustCompile(`[^\w.\-]+`)
+
+// ImportOpenApi creates an endpoint for each operation of an OpenAPI 3 or Swagger 2 spec,
+// in yaml or json. With examples, a request is also created for each operation whose
+// parameters have example values.
+func ImportOpenApi(source []byte, examples bool) (*ConfigurationWriter, error) {
+	spec := make(map[interface{}]interface{})
+	if err := yaml.Unmarshal(source, &spec); err != nil {
+		return nil, err
+	}
+	if spec["openapi"] == nil && spec["swagger"] == nil {
+		return nil, errors.New("Not an OpenAPI or Swagger spec")
+	}
+
+	writer := &ConfigurationWriter{Variables: make(map[string]interface{})}
+	readOpenApiServer(spec, writer)
+
+	paths := asMap(spec["paths"])
+	pathNames := make([]string, 0, len(paths))
+	for path := range paths {
+		pathNames = append(pathNames, fmt.Sprint(path))
+	}
+	sort.Strings(pathNames)
+
+	names := make(map[string]bool)
+	for _, path := range pathNames {
+		pathItem := asMap(resolveReference(spec, paths[path]))
+		for _, method := range openApiMethods {
+			operation := asMap(pathItem[method])
+			if operation == nil {
+				continue
+			}
+			endpoint, example := readOpenApiOperation(spec, path, method, pathItem, operation)
+			endpoint.Name = uniqueName(names, endpoint.Name)
+			writer.Endpoints = append(writer.Endpoints, endpoint)
+			if examples && len(example) > 0 {
+				example[ENDPOINT] = endpoint.Name
+				writer.Requests = append(writer.Requests, &Request{Name: endpoint.Name + "_example", Parameters: example})
+			}
+		}
+	}
+	if len(writer.Endpoints) == 0 {
+		return nil, errors.New("No operations found in spec")
+	}
+	return writer, nil
+}
+
+// readOpenApiServer takes the url from the first server, or from host and basePath in Swagger 2.
+// Server variables become global variables with their default value.
+func readOpenApiServer(spec map[interface{}]interface{}, writer *ConfigurationWriter) {
+	if servers, ok := spec["servers"].([]interface{}); ok && len(servers) > 0 {
+		server := asMap(servers[0])
+		writer.Url = strings.TrimSuffix(asString(server["url"]), "/")
+		for name, variable := range asMap(server["variables"]) {
+			if value, ok := asMap(variable)["default"]; ok {
+				writer.Variables[fmt.Sprint(name)] = value
+			}
+		}
+		return
+	}
+	if host := asString(spec["host"]); host != "" {
+		scheme := "https"
+		if schemes, ok := spec["schemes"].([]interface{}); ok && len(schemes) > 0 {
+			scheme = asString(schemes[0])
+		}
+		writer.Url = scheme + "://" + host + strings.TrimSuffix(asString(spec["basePath"]), "/")
+	}
+}
+
+// readOpenApiOperation returns the endpoint of an operation and the example values of its parameters.
+func readOpenApiOperation(spec map[interface{}]interface{}, path string, method string, pathItem map[interface{}]interface{}, operation map[interface{}]interface{}) (*Endpoint, map[interface{}]interface{}) {
+	endpoint := &Endpoint{
+		Path:       path,
+		Method:     strings.ToUpper(method),
+		QueryList:  make(map[string]string),
+		Parameters: make(map[string]interface{}),
+	}
+	if operationId := asString(operation["operationId"]); operationId != "" {
+		endpoint.Name = snakeCase(operationId)
+	} else {
+		endpoint.Name = nameFromPath(method, path)
+	}
+
+	example := make(map[interface{}]interface{})
+	variables := make(map[interface{}]interface{})
+	for _, parameter := range openApiParameters(spec, pathItem, operation) {
+		name := asString(parameter["name"])
+		variable := uniqueVariable(variables, nonVariableCharacters.ReplaceAllString(name, "_"))
+		variables[variable] = name
+		switch asString(parameter["in"]) {
+		case "query":
+			endpoint.QueryList[name] = "{" + variable + "}"
+			endpoint.QueryListKeys = append(endpoint.QueryListKeys, name)
+		case "header":
+			if required, _ := parameter["required"].(bool); !required {
+				continue
+			}
+			endpoint.Headers = append(endpoint.Headers, name+": {"+variable+"}")
+		case "body":
+			if body := openApiExample(spec, parameter); body != nil {
+				endpoint.Body = exampleBody(body)
+			}
+			if consumes, ok := operationConsumes(spec, operation); ok {
+				endpoint.Headers = append(endpoint.Headers, "Content-Type: "+consumes)
+			}
+			continue
+		case "path":
+			endpoint.Path = strings.Replace(endpoint.Path, "{"+name+"}", "{"+variable+"}", -1)
+		default:
+			continue
+		}
+		if value := openApiExample(spec, parameter); value != nil {
+			example[variable] = value
+		}
+	}
+
+	if requestBody := asMap(resolveReference(spec, operation["requestBody"])); requestBody != nil {
+		content := asMap(requestBody["content"])
+		if contentType := preferredContentType(content); contentType != "" {
+			endpoint.Headers = append(endpoint.Headers, "Content-Type: "+contentType)
+			if body := openApiExample(spec, asMap(content[contentType])); body != nil {
+				endpoint.Body = exampleBody(body)
+			}
+		}
+	}
+	return endpoint, example
+}
+
+// openApiParameters merges the path and operation parameters, the operation ones taking precedence.
+func openApiParameters(spec map[interface{}]interface{}, pathItem map[interface{}]interface{}, operation map[interface{}]interface{}) []map[interface{}]interface{} {
+	var parameters []map[interface{}]interface{}
+	index := make(map[string]int)
+	for _, list := range []interface{}{pathItem["parameters"], operation["parameters"]} {
+		elements, _ := list.([]interface{})
+		for i := range elements {
+			parameter := asMap(resolveReference(spec, elements[i]))
+			if parameter == nil {
+				continue
+			}
+			key := asString(parameter["in"]) + ":" + asString(parameter["name"])
+			if position, ok := index[key]; ok {
+				parameters[position] = parameter
+				continue
+			}
+			index[key] = len(parameters)
+			parameters = append(parameters, parameter)
+		}
+	}
+	return parameters
+}
+
+// openApiExample looks for an example in a parameter or media type, then in its schema.
+func openApiExample(spec map[interface{}]interface{}, definition map[interface{}]interface{}) interface{} {
+	if definition == nil {
+		return nil
+	}
+	if example, ok := definition["example"]; ok {
+		return example
+	}
+	examples := asMap(definition["examples"])
+	keys := make([]string, 0, len(examples))
+	for key := range examples {
+		keys = append(keys, fmt.Sprint(key))
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if value, ok := asMap(resolveReference(spec, examples[key]))["value"]; ok {
+			return value
+		}
+	}
+	schema := asMap(resolveReference(spec, definition["schema"]))
+	if example, ok := schema["example"]; ok {
+		return example
+	}
+	return nil
+}
+
+func operationConsumes(spec map[interface{}]interface{}, operation map[interface{}]interface{}) (string, bool) {
+	for _, consumes := range []interface{}{operation["consumes"], spec["consumes"]} {
+		if list, ok := consumes.([]interface{}); ok && len(list) > 0 {
+			return asString(list[0]), true
+		}
+	}
+	return "", false
+}
+
+// preferredContentType picks json when available, otherwise the first content type.
+func preferredContentType(content map[interface{}]interface{}) string {
+	contentTypes := make([]string, 0, len(content))
+	for contentType := range content {
+		if fmt.Sprint(contentType) == "application/json" {
+			return "application/json"
+		}
+		contentTypes = append(contentTypes, fmt.Sprint(contentType))
+	}
+	sort.Strings(contentTypes)
+	if len(contentTypes) == 0 {
+		return ""
+	}
+	return contentTypes[0]
+}
+
+func exampleBody(example interface{}) string {
+	if text, ok := example.(string); ok {
+		return text
+	}
+	asJson, err := json.MarshalIndent(toJsonValue(example), "", "  ")
+	if err != nil {
+		return fmt.Sprint(example)
+	}
+	return string(asJson)
+}
+
+// resolveReference follows local references like '#/components/parameters/id'.
+func resolveReference(spec map[interface{}]interface{}, value interface{}) interface{} {
+	for i := 0; i < 10; i++ {
+		reference := asString(asMap(value)["$ref"])
+		if !strings.HasPrefix(reference, "#/") {
+			return value
+		}
+		var current interface{} = spec
+		for _, key := range strings.Split(strings.TrimPrefix(reference, "#/"), "/") {
+			key = strings.Replace(strings.Replace(key, "~1", "/", -1), "~0", "~", -1)
+			current = asMap(current)[key]
+		}
+		value = current
+	}
+	return value
+}
+
+func asMap(value interface{}) map[interface{}]interface{} {
+	asMap, _ := value.(map[interface{}]interface{})
+	return asMap
+}
+
+func asString(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+// snakeCase converts names like getUserById to get_user_by_id.
+func snakeCase(name string) string {
+	var snake strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+			(i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+			snake.WriteRune('_')
+		}
+		snake.WriteRune(unicode.ToLower(r))
+	}
+	return strings.Trim(nonNameCharacters.ReplaceAllString(snake.String(), "_"), "_")
+}
+
+func uniqueName(names map[string]bool, name string) string {
+	unique := name
+	for i := 2; names[unique]; i++ {
+		unique = fmt.Sprintf("%v_%v", name, i)
+	}
+	names[unique] = true
+	return unique
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+func TestImportOpenApi(t *testing.T) {
+	source, _ := ioutil.ReadFile("_resources/openapi/petstore.yaml")
+	writer, err := ImportOpenApi(source, true)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if writer.Url != "https://{environment}.petstore.io/v1" || writer.Variables["environment"] != "api" {
+		t.Errorf("Unexpected url %v %v", writer.Url, writer.Variables)
+	}
+
+	names := make([]string, 0, len(writer.Endpoints))
+	for _, endpoint := range writer.Endpoints {
+		names = append(names, endpoint.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"list_pets", "create_pet", "show_pet_by_id", "delete_pets_petid"}) {
+		t.Errorf("Unexpected endpoints %v", names)
+	}
+
+	listPets := writer.Endpoints[0]
+	if !reflect.DeepEqual(listPets.QueryListKeys, []string{"limit"}) || listPets.QueryList["limit"] != "{limit}" {
+		t.Errorf("Unexpected query %v", listPets.QueryList)
+	}
+	if !reflect.DeepEqual(listPets.Headers, []string{"X-Request-Id: {X-Request-Id}"}) {
+		t.Errorf("Only required headers expected %v", listPets.Headers)
+	}
+
+	createPet := writer.Endpoints[1]
+	if createPet.Method != "POST" || createPet.Body != "{\n  \"name\": \"rex\"\n}" {
+		t.Errorf("Unexpected body %v %v", createPet.Method, createPet.Body)
+	}
+	if !reflect.DeepEqual(createPet.Headers, []string{"Content-Type: application/json"}) {
+		t.Errorf("Json should be preferred %v", createPet.Headers)
+	}
+
+	if len(writer.Requests) != 3 {
+		t.Errorf("Expected 3 example requests, got %v", len(writer.Requests))
+		return
+	}
+	if writer.Requests[2].Name != "delete_pets_petid_example" || writer.Requests[2].Parameters["petId"] != 2 {
+		t.Errorf("Operation parameters should override path ones %v", writer.Requests[2].Parameters)
+	}
+}
+
+func TestImportOpenApiPathVariables(t *testing.T) {
+	writer, err := ImportOpenApi([]byte(`
+openapi: 3.0.0
+paths:
+  /users/{user-id}/pets/{pet[id]}:
+    get:
+      operationId: getPet
+      parameters:
+        - name: user-id
+          in: path
+          example: 7
+        - name: pet[id]
+          in: path
+          example: 9
+`), true)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if path := writer.Endpoints[0].Path; path != "/users/{user-id}/pets/{pet_id_}" {
+		t.Errorf("Should have used the variables in the path but got %v", path)
+	}
+	if example := writer.Requests[0].Parameters; example["user-id"] != 7 || example["pet_id_"] != 9 {
+		t.Errorf("Unexpected example %v", example)
+	}
+}
+
+func TestImportOpenApiAttributeNames(t *testing.T) {
+	writer, err := ImportOpenApi([]byte(`
+openapi: 3.0.0
+paths:
+  /items/{data}:
+    get:
+      operationId: getItem
+      parameters:
+        - name: data
+          in: path
+          example: 7
+        - name: body
+          in: query
+          example: full
+`), true)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	endpoint := writer.Endpoints[0]
+	if endpoint.Path != "/items/{data2}" || endpoint.QueryList["body"] != "{body2}" {
+		t.Errorf("Should have renamed the variables named like request attributes %v %v", endpoint.Path, endpoint.QueryList)
+	}
+	if example := writer.Requests[0].Parameters; example["data2"] != 7 || example["body2"] != "full" || example["data"] != nil {
+		t.Errorf("Unexpected example %v", example)
+	}
+}
+
+func TestImportOpenApiLoadable(t *testing.T) {
+	source, _ := ioutil.ReadFile("_resources/openapi/petstore.yaml")
+	writer, _ := ImportOpenApi(source, true)
+
+	var b bytes.Buffer
+	writer.Write(&b)
+
+	conf, err := NewConfiguration(&MockReader{configurations: map[string][]byte{"test": b.Bytes()}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	request := conf.Requests["show_pet_by_id_example"]
+	if request == nil || request.Url != "https://api.petstore.io/v1" || request.Path != "/pets/1" {
+		t.Errorf("Unexpected request %v", request)
+	}
+}
+
+func TestImportSwagger(t *testing.T) {
+	source := []byte(`{
+  "swagger": "2.0",
+  "host": "localhost:8080",
+  "basePath": "/api",
+  "schemes": ["http"],
+  "consumes": ["application/json"],
+  "paths": {
+    "/users": {
+      "post": {
+        "parameters": [
+          {"name": "body", "in": "body", "schema": {"example": {"name": "gohit"}}},
+          {"name": "filter[name]", "in": "query"}
+        ]
+      }
+    }
+  }
+}`)
+	writer, err := ImportOpenApi(source, false)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if writer.Url != "http://localhost:8080/api" {
+		t.Errorf("Unexpected url %v", writer.Url)
+	}
+	endpoint := writer.Endpoints[0]
+	if endpoint.Name != "post_users" || endpoint.QueryList["filter[name]"] != "{filter_name_}" {
+		t.Errorf("Unexpected endpoint %v", endpoint)
+	}
+	if endpoint.Body != "{\n  \"name\": \"gohit\"\n}" || !reflect.DeepEqual(endpoint.Headers, []string{"Content-Type: application/json"}) {
+		t.Errorf("Unexpected body %v %v", endpoint.Body, endpoint.Headers)
+	}
+	if len(writer.Requests) != 0 {
+		t.Error("Requests only expected with examples")
+	}
+}
+
+func TestImportOpenApiInvalid(t *testing.T) {
+	if _, err := ImportOpenApi([]byte("url: http://localhost"), false); err == nil {
+		t.Error("Should fail when not a spec")
+	}
+	if _, err := ImportOpenApi([]byte("openapi: 3.0.0\npaths: {}"), false); err == nil {
+		t.Error("Should fail without operations")
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	for name, expected := range map[string]string{"getUserById": "get_user_by_id", "listHTTPServers": "list_http_servers", "create-pet": "create_pet", "v2Items": "v2_items"} {
+		if snakeCase(name) != expected {
+			t.Errorf("Expected %v, got %v", expected, snakeCase(name))
+		}
+	}
+}
@@ -0,0 +1,427 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const POSTMAN_SCHEMA = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+var postmanVariablePattern = regexp.MustCompile(`{{\s*([^{}]+?)\s*}}`)
+
+// gohitPlaceholderPattern matches the environment placeholders and the {variables}, including Postman dynamic ones.
+var gohitPlaceholderPattern = regexp.MustCompile(`{env:([\w.\-]+)}|\$\{([\w.\-]+)}|{(\$?[\w.\-]+)}`)
+
+// The subset of the Postman collection v2.1 and environment formats understood by gohit.
+
+type postmanCollection struct {
+	Info     postmanInfo        `json:"info"`
+	Item     []*postmanItem     `json:"item"`
+	Auth     *postmanAuth       `json:"auth,omitempty"`
+	Variable []*postmanVariable `json:"variable,omitempty"`
+}
+
+type postmanInfo struct {
+	Name   string `json:"name"`
+	Schema string `json:"schema"`
+}
+
+type postmanItem struct {
+	Name    string          `json:"name"`
+	Item    []*postmanItem  `json:"item,omitempty"`
+	Request *postmanRequest `json:"request,omitempty"`
+	Auth    *postmanAuth    `json:"auth,omitempty"`
+}
+
+type postmanRequest struct {
+	Method string             `json:"method"`
+	Header []*postmanVariable `json:"header"`
+	Url    postmanUrl         `json:"url"`
+	Body   *postmanBody       `json:"body,omitempty"`
+	Auth   *postmanAuth       `json:"auth,omitempty"`
+}
+
+// postmanUrl is either a string or an object with the raw url and the values of the :path variables.
+type postmanUrl struct {
+	Raw      string             `json:"raw"`
+	Variable []*postmanVariable `json:"variable,omitempty"`
+}
+
+type postmanBody struct {
+	Mode       string             `json:"mode"`
+	Raw        string             `json:"raw,omitempty"`
+	Urlencoded []*postmanVariable `json:"urlencoded,omitempty"`
+	Formdata   []*postmanVariable `json:"formdata,omitempty"`
+	Graphql    *postmanGraphql    `json:"graphql,omitempty"`
+}
+
+type postmanGraphql struct {
+	Query     string `json:"query"`
+	Variables string `json:"variables,omitempty"`
+}
+
+type postmanAuth struct {
+	Type   string             `json:"type"`
+	Basic  []*postmanVariable `json:"basic,omitempty"`
+	Bearer []*postmanVariable `json:"bearer,omitempty"`
+	Apikey []*postmanVariable `json:"apikey,omitempty"`
+}
+
+type postmanVariable struct {
+	Key      string      `json:"key"`
+	Value    interface{} `json:"value"`
+	Type     string      `json:"type,omitempty"`
+	Src      interface{} `json:"src,omitempty"`
+	Disabled bool        `json:"disabled,omitempty"`
+	Enabled  *bool       `json:"enabled,omitempty"`
+}
+
+type postmanEnvironment struct {
+	Name   string             `json:"name"`
+	Values []*postmanVariable `json:"values"`
+}
+
+func (u *postmanUrl) UnmarshalJSON(source []byte) error {
+	var raw string
+	if err := json.Unmarshal(source, &raw); err == nil {
+		u.Raw = raw
+		return nil
+	}
+	type plain postmanUrl
+	return json.Unmarshal(source, (*plain)(u))
+}
+
+func (variable *postmanVariable) enabled() bool {
+	return !variable.Disabled && (variable.Enabled == nil || *variable.Enabled)
+}
+
+func (variable *postmanVariable) value() string {
+	return toGohitVariables(asString(variable.Value))
+}
+
+// ImportPostman creates an endpoint for each request of a collection, folders included.
+// Collection variables become global variables and the environment, if any, a gohit environment.
+func ImportPostman(source []byte, environmentSource []byte) (*ConfigurationWriter, error) {
+	collection := &postmanCollection{}
+	if err := json.Unmarshal(source, collection); err != nil {
+		return nil, err
+	}
+
+	writer := &ConfigurationWriter{Variables: make(map[string]interface{})}
+	for _, variable := range collection.Variable {
+		if variable.enabled() {
+			writer.Variables[postmanVariableName(variable.Key)] = variable.value()
+		}
+	}
+
+	if environmentSource != nil {
+		environment := &postmanEnvironment{}
+		if err := json.Unmarshal(environmentSource, environment); err != nil {
+			return nil, err
+		}
+		imported := &Environment{Name: nameFromPath("", environment.Name), Variables: make(map[string]interface{})}
+		for _, variable := range environment.Values {
+			if variable.enabled() {
+				imported.Variables[postmanVariableName(variable.Key)] = variable.value()
+			}
+		}
+		writer.Environments = append(writer.Environments, imported)
+	}
+
+	names := make(map[string]bool)
+	if err := importPostmanItems(writer, names, collection.Item, collection.Auth); err != nil {
+		return nil, err
+	}
+	if len(writer.Endpoints) == 0 {
+		return nil, errors.New("No requests found in collection")
+	}
+	return writer, nil
+}
+
+func importPostmanItems(writer *ConfigurationWriter, names map[string]bool, items []*postmanItem, auth *postmanAuth) error {
+	for _, item := range items {
+		if item.Request == nil {
+			folderAuth := auth
+			if item.Auth != nil {
+				folderAuth = item.Auth
+			}
+			if err := importPostmanItems(writer, names, item.Item, folderAuth); err != nil {
+				return err
+			}
+			continue
+		}
+		endpoint, pathVariables, err := importPostmanRequest(item.Request, auth)
+		if err != nil {
+			return errors.New(fmt.Sprintf("Request '%v': %v", item.Name, err))
+		}
+		endpoint.Name = uniqueName(names, nameFromPath("", item.Name))
+		writer.Endpoints = append(writer.Endpoints, endpoint)
+		if len(pathVariables) > 0 {
+			pathVariables[ENDPOINT] = endpoint.Name
+			writer.Requests = append(writer.Requests, &Request{Name: endpoint.Name + "_request", Parameters: pathVariables})
+		}
+	}
+	return nil
+}
+
+// importPostmanRequest returns the endpoint of a request and the values of its :path variables.
+func importPostmanRequest(request *postmanRequest, auth *postmanAuth) (*Endpoint, map[interface{}]interface{}, error) {
+	endpoint := &Endpoint{
+		Method:     strings.ToUpper(request.Method),
+		QueryList:  make(map[string]string),
+		Parameters: make(map[string]interface{}),
+	}
+	if endpoint.Method == "" {
+		endpoint.Method = "GET"
+	}
+
+	raw := toGohitVariables(request.Url.Raw)
+	if i := strings.Index(raw, "#"); i >= 0 {
+		raw = raw[:i]
+	}
+	if i := strings.Index(raw, "?"); i >= 0 {
+		raw, endpoint.QueryRaw = raw[:i], raw[i+1:]
+	}
+	start := 0
+	if i := strings.Index(raw, "://"); i >= 0 {
+		start = i + 3
+	}
+	if i := strings.Index(raw[start:], "/"); i >= 0 {
+		endpoint.Url, endpoint.Path = raw[:start+i], raw[start+i:]
+	} else {
+		endpoint.Url, endpoint.Path = raw, "/"
+	}
+	if endpoint.Url == "" {
+		return nil, nil, errors.New("missing url")
+	}
+
+	segments := strings.Split(endpoint.Path, "/")
+	for i := range segments {
+		if strings.HasPrefix(segments[i], ":") && len(segments[i]) > 1 {
+			segments[i] = "{" + postmanVariableName(segments[i][1:]) + "}"
+		}
+	}
+	endpoint.Path = strings.Join(segments, "/")
+	pathVariables := make(map[interface{}]interface{})
+	for _, variable := range request.Url.Variable {
+		if value := variable.value(); value != "" {
+			pathVariables[postmanVariableName(variable.Key)] = value
+		}
+	}
+
+	for _, header := range request.Header {
+		if header.enabled() {
+			endpoint.Headers = append(endpoint.Headers, toGohitVariables(header.Key)+": "+header.value())
+		}
+	}
+
+	if request.Auth != nil {
+		auth = request.Auth
+	}
+	importPostmanAuth(endpoint, auth)
+	importPostmanBody(endpoint, request.Body)
+	return endpoint, pathVariables, nil
+}
+
+func importPostmanAuth(endpoint *Endpoint, auth *postmanAuth) {
+	if auth == nil {
+		return
+	}
+	switch auth.Type {
+	case "basic":
+		endpoint.Options = append(endpoint.Options, "-u "+postmanAuthValue(auth.Basic, "username")+":"+postmanAuthValue(auth.Basic, "password"))
+	case "bearer":
+		endpoint.Headers = append(endpoint.Headers, "Authorization: Bearer "+postmanAuthValue(auth.Bearer, "token"))
+	case "apikey":
+		if postmanAuthValue(auth.Apikey, "in") == "query" {
+			endpoint.QueryRaw = strings.TrimPrefix(endpoint.QueryRaw+"&"+postmanAuthValue(auth.Apikey, "key")+"="+postmanAuthValue(auth.Apikey, "value"), "&")
+		} else {
+			endpoint.Headers = append(endpoint.Headers, postmanAuthValue(auth.Apikey, "key")+": "+postmanAuthValue(auth.Apikey, "value"))
+		}
+	}
+}
+
+func postmanAuthValue(values []*postmanVariable, key string) string {
+	for _, value := range values {
+		if value.Key == key {
+			return value.value()
+		}
+	}
+	return ""
+}
+
+func importPostmanBody(endpoint *Endpoint, body *postmanBody) {
+	if body == nil {
+		return
+	}
+	switch body.Mode {
+	case "raw":
+		endpoint.Body = toGohitVariables(body.Raw)
+	case "urlencoded":
+		var pairs []string
+		for _, parameter := range body.Urlencoded {
+			if parameter.enabled() {
+				pairs = append(pairs, toGohitVariables(parameter.Key)+"="+parameter.value())
+			}
+		}
+		endpoint.Body = strings.Join(pairs, "&")
+		endpoint.Headers = mergeHeaders(endpoint.Headers, []string{"+Content-Type: application/x-www-form-urlencoded"})
+	case "formdata":
+		for _, parameter := range body.Formdata {
+			if !parameter.enabled() {
+				continue
+			}
+			if parameter.Type == "file" {
+				endpoint.Options = append(endpoint.Options, curlOption("-F", parameter.Key+"=@"+asString(parameter.Src)))
+			} else {
+				endpoint.Options = append(endpoint.Options, curlOption("-F", toGohitVariables(parameter.Key)+"="+parameter.value()))
+			}
+		}
+	case "graphql":
+		if body.Graphql == nil {
+			return
+		}
+		graphql := map[string]interface{}{"query": body.Graphql.Query}
+		var variables interface{}
+		if json.Unmarshal([]byte(body.Graphql.Variables), &variables) == nil {
+			graphql["variables"] = variables
+		}
+		asJson, _ := json.Marshal(graphql)
+		endpoint.Body = string(asJson)
+	}
+}
+
+// ExportPostman writes the requests as a Postman collection, in a folder per endpoint.
+// Endpoints without requests are exported as they are. The variables not set on the requests,
+// like the yaml variables, are kept as {{variables}}.
+func ExportPostman(conf *Configuration, name string, out io.Writer) error {
+	collection := &postmanCollection{
+		Info: postmanInfo{Name: name, Schema: POSTMAN_SCHEMA},
+		Item: []*postmanItem{},
+	}
+	for _, key := range sortedKeys(conf.GlobalVariables) {
+		collection.Variable = append(collection.Variable, &postmanVariable{Key: key, Value: toPostmanVariables(conf.getReplacement(conf.GlobalVariables[key]))})
+	}
+
+	requests := make(map[string][]string)
+	for requestName, request := range conf.Requests {
+		endpointName := fmt.Sprint(request.Parameters[ENDPOINT])
+		requests[endpointName] = append(requests[endpointName], requestName)
+	}
+
+	endpointNames := make([]string, 0, len(conf.Endpoints))
+	for endpointName := range conf.Endpoints {
+		endpointNames = append(endpointNames, endpointName)
+	}
+	sort.Strings(endpointNames)
+	for _, endpointName := range endpointNames {
+		folder := &postmanItem{Name: endpointName}
+		sort.Strings(requests[endpointName])
+		for _, requestName := range requests[endpointName] {
+			request, _, err := conf.newRequestDefinition(requestName, conf.Requests[requestName].Parameters)
+			if err != nil {
+				return err
+			}
+			// only the variables set on the request are replaced, the others stay variables
+			for k, v := range request.Parameters {
+				conf.replaceAll(request, "{"+k.(string)+"}", v)
+			}
+			folder.Item = append(folder.Item, &postmanItem{Name: requestName, Request: exportPostmanRequest(request)})
+		}
+		if len(folder.Item) == 0 {
+			endpoint := conf.Endpoints[endpointName]
+			folder.Item = append(folder.Item, &postmanItem{Name: endpointName, Request: exportPostmanRequest(endpoint.asRequest())})
+		}
+		collection.Item = append(collection.Item, folder)
+	}
+
+	return writePostmanJson(collection, out)
+}
+
+func exportPostmanRequest(request *Request) *postmanRequest {
+	exported := &postmanRequest{Method: request.Method, Header: []*postmanVariable{}}
+
+	var query []string
+	if request.QueryRaw != "" {
+		query = append(query, request.QueryRaw)
+	}
+	for _, key := range request.QueryListKeys {
+		query = append(query, key+"="+request.QueryList[key])
+	}
+	exported.Url.Raw = toPostmanVariables(request.Url + request.Path)
+	if len(query) > 0 {
+		exported.Url.Raw += "?" + toPostmanVariables(strings.Join(query, "&"))
+	}
+
+	for _, header := range request.Headers {
+		parts := strings.SplitN(header, ":", 2)
+		if len(parts) == 2 {
+			exported.Header = append(exported.Header, &postmanVariable{Key: strings.TrimSpace(parts[0]), Value: toPostmanVariables(strings.TrimSpace(parts[1]))})
+		}
+	}
+
+	for _, option := range request.Options {
+		parts := strings.Fields(option)
+		if len(parts) == 2 && (parts[0] == "-u" || parts[0] == "--user") {
+			credentials := strings.SplitN(strings.Trim(toPostmanVariables(parts[1]), `'"`), ":", 2)
+			if len(credentials) == 1 {
+				credentials = append(credentials, "")
+			}
+			exported.Auth = &postmanAuth{Type: "basic", Basic: []*postmanVariable{
+				{Key: "username", Value: credentials[0], Type: "string"},
+				{Key: "password", Value: credentials[1], Type: "string"},
+			}}
+		}
+	}
+
+	if request.Body != "" {
+		exported.Body = &postmanBody{Mode: "raw", Raw: toPostmanVariables(request.Body)}
+	}
+	return exported
+}
+
+// ExportPostmanEnvironment writes the variables of an environment as a Postman environment.
+func ExportPostmanEnvironment(conf *Configuration, name string, out io.Writer) error {
+	environment := conf.Environments[name]
+	if environment == nil {
+		return errors.New(fmt.Sprintf("Environment '%v' not found", name))
+	}
+	exported := &postmanEnvironment{Name: name, Values: []*postmanVariable{}}
+	enabled := true
+	for _, key := range sortedKeys(environment.Variables) {
+		exported.Values = append(exported.Values, &postmanVariable{Key: key, Value: toPostmanVariables(conf.getReplacement(environment.Variables[key])), Enabled: &enabled})
+	}
+	return writePostmanJson(exported, out)
+}
+
+func writePostmanJson(value interface{}, out io.Writer) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+// toGohitVariables converts Postman {{variables}} to gohit {variables}.
+func toGohitVariables(value string) string {
+	return postmanVariablePattern.ReplaceAllStringFunc(value, func(match string) string {
+		return "{" + postmanVariableName(postmanVariablePattern.FindStringSubmatch(match)[1]) + "}"
+	})
+}
+
+// toPostmanVariables converts gohit {variables} and environment placeholders to Postman {{variables}}.
+func toPostmanVariables(value string) string {
+	return gohitPlaceholderPattern.ReplaceAllString(value, "{{$1$2$3}}")
+}
+
+// postmanVariableName keeps dynamic variables like $guid, other characters not allowed by gohit become '_'.
+func postmanVariableName(name string) string {
+	if strings.HasPrefix(name, "$") {
+		return name
+	}
+	return nonVariableCharacters.ReplaceAllString(name, "_")
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+func TestImportPostman(t *testing.T) {
+	collection, _ := ioutil.ReadFile("_resources/postman/collection.json")
+	environment, _ := ioutil.ReadFile("_resources/postman/environment.json")
+	writer, err := ImportPostman(collection, environment)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if !reflect.DeepEqual(writer.Variables, map[string]interface{}{"baseUrl": "https://api.example.com", "name": "gohit"}) {
+		t.Errorf("Unexpected variables %v", writer.Variables)
+	}
+	if len(writer.Environments) != 1 || writer.Environments[0].Name != "staging" || len(writer.Environments[0].Variables) != 2 {
+		t.Errorf("Unexpected environments %v", writer.Environments)
+	}
+
+	if len(writer.Endpoints) != 3 {
+		t.Errorf("Expected 3 endpoints, got %v", len(writer.Endpoints))
+		return
+	}
+	getUser := writer.Endpoints[0]
+	if getUser.Name != "get_user" || getUser.Url != "{baseUrl}" || getUser.Path != "/users/{id}" || getUser.QueryRaw != "fields=name" {
+		t.Errorf("Unexpected endpoint %v", getUser)
+	}
+	if !reflect.DeepEqual(getUser.Headers, []string{"Accept: application/json", "Authorization: Bearer {token}"}) {
+		t.Errorf("Unexpected headers %v", getUser.Headers)
+	}
+
+	createUser := writer.Endpoints[1]
+	if createUser.Method != "POST" || createUser.Body != `{"name": "{name}"}` {
+		t.Errorf("Unexpected endpoint %v", createUser)
+	}
+
+	login := writer.Endpoints[2]
+	if !reflect.DeepEqual(login.Options, []string{"-u admin:{password}"}) || login.Body != "grant_type=client_credentials&scope=all" {
+		t.Errorf("Request auth should override the collection one %v %v", login.Options, login.Body)
+	}
+
+	if len(writer.Requests) != 1 || writer.Requests[0].Name != "get_user_request" || writer.Requests[0].Parameters["id"] != "1" {
+		t.Errorf("Unexpected requests %v", writer.Requests)
+	}
+}
+
+func TestImportPostmanLoadable(t *testing.T) {
+	collection, _ := ioutil.ReadFile("_resources/postman/collection.json")
+	environment, _ := ioutil.ReadFile("_resources/postman/environment.json")
+	writer, _ := ImportPostman(collection, environment)
+
+	var b bytes.Buffer
+	writer.Write(&b)
+
+	conf, err := NewEnvironmentConfiguration(&MockReader{configurations: map[string][]byte{"test": b.Bytes()}}, "staging")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	request := conf.Requests["get_user_request"]
+	if request.Url != "https://staging.example.com" || request.Path != "/users/1" || request.Headers[1] != "Authorization: Bearer abc" {
+		t.Errorf("Unexpected request %v %v", request, request.Headers)
+	}
+}
+
+func TestExportPostman(t *testing.T) {
+	reader := &MockReader{configurations: make(map[string][]byte)}
+	reader.configurations["test"] = []byte(
+		`
+url: http://localhost
+
+headers:
+  - 'Authorization: {env:TOKEN}'
+
+environments:
+  dev:
+    variables:
+      version: 2
+
+endpoints:
+  get_item:
+    path: /items/{id}
+    options:
+      - '-u user:{password}'
+  create_item:
+    path: /items
+    method: POST
+    body:
+      name: '{name}'
+
+requests:
+  get_item_1:
+    endpoint: get_item
+    id: 1
+`)
+	conf, err := NewConfiguration(reader)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	var b bytes.Buffer
+	if err := ExportPostman(conf, "items", &b); err != nil {
+		t.Error(err)
+		return
+	}
+
+	writer, err := ImportPostman(b.Bytes(), nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(writer.Endpoints) != 2 {
+		t.Errorf("Expected 2 endpoints, got %v", len(writer.Endpoints))
+		return
+	}
+	createItem := writer.Endpoints[0]
+	if createItem.Name != "create_item" || createItem.Method != "POST" || createItem.Body != `{"name":"{name}"}` {
+		t.Errorf("Unexpected endpoint %v", createItem)
+	}
+	getItem := writer.Endpoints[1]
+	if getItem.Name != "get_item_1" || getItem.Url != "http://localhost" || getItem.Path != "/items/1" {
+		t.Errorf("Unexpected endpoint %v", getItem)
+	}
+	if !reflect.DeepEqual(getItem.Headers, []string{"Authorization: {TOKEN}"}) || !reflect.DeepEqual(getItem.Options, []string{"-u user:{password}"}) {
+		t.Errorf("Unexpected headers or options %v %v", getItem.Headers, getItem.Options)
+	}
+
+	b.Reset()
+	if err := ExportPostmanEnvironment(conf, "dev", &b); err != nil {
+		t.Error(err)
+	}
+	environment := &postmanEnvironment{}
+	json.Unmarshal(b.Bytes(), environment)
+	if environment.Name != "dev" || len(environment.Values) != 1 || environment.Values[0].Value != "2" {
+		t.Errorf("Unexpected environment %v", b.String())
+	}
+	if err := ExportPostmanEnvironment(conf, "prod", &b); err == nil {
+		t.Error("Should fail on missing environment")
+	}
+}
+
+func TestExportPostmanKeepsVariables(t *testing.T) {
+	reader := &MockReader{configurations: make(map[string][]byte)}
+	reader.configurations["test"] = []byte(
+		`
+url: http://{host}
+
+variables:
+  host: localhost:8080
+  version: 2
+
+endpoints:
+  get_item:
+    path: /v{version}/items/{id}
+    headers:
+      - 'X-Host: {host}'
+
+requests:
+  get_item_1:
+    endpoint: get_item
+    id: 1
+`)
+	conf, err := NewConfiguration(reader)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	var b bytes.Buffer
+	if err := ExportPostman(conf, "items", &b); err != nil {
+		t.Error(err)
+		return
+	}
+	collection := &postmanCollection{}
+	json.Unmarshal(b.Bytes(), collection)
+	request := collection.Item[0].Item[0].Request
+	if request.Url.Raw != "http://{{host}}/v{{version}}/items/1" || request.Header[0].Value != "{{host}}" {
+		t.Errorf("Should have kept the yaml variables but got %v %v", request.Url.Raw, request.Header[0].Value)
+	}
+	if len(collection.Variable) != 2 || collection.Variable[0].Key != "host" || collection.Variable[0].Value != "localhost:8080" {
+		t.Errorf("Unexpected collection variables %v", b.String())
+	}
+}
+
+func TestPostmanVariables(t *testing.T) {
+	if toGohitVariables("{{ baseUrl }}/{{$guid}}/{{a b}}") != "{baseUrl}/{$guid}/{a_b}" {
+		t.Errorf("Unexpected %v", toGohitVariables("{{ baseUrl }}/{{$guid}}/{{a b}}"))
+	}
+	if toPostmanVariables(`{"a": "{b}", "c": "{env:C}", "d": "${D}", "e": "{$guid}"}`) != `{"a": "{{b}}", "c": "{{C}}", "d": "{{D}}", "e": "{{$guid}}"}` {
+		t.Errorf("Unexpected %v", toPostmanVariables(`{"a": "{b}", "c": "{env:C}", "d": "${D}", "e": "{$guid}"}`))
+	}
+}
@@ -9,51 +9,65 @@ import (
 	"text/template"
 )
 
-const showCurlTemplate = `curl '{{.Url}}{{.Path}}{{if .QueryRaw}}?{{.QueryRaw}}{{end}}' \
+const showCurlTemplate = `curl '{{.Url}}{{.Path}}{{.UrlQuery}}' \
 {{- if .Headers}}
-        {{- range $key, $value := .Headers }}
-        -H '{{$key}}' \
+        {{- range .Headers }}
+        -H '{{.}}' \
         {{- end}}
 {{- end}}
-{{- if .QueryList}}
+{{- if and .QueryList (not .Body)}}
         -G \
         {{- range $key := .QueryListKeys }}
         --data-urlencode '{{$key}}={{index $.QueryList $key }}' \
         {{- end}}
 {{- end}}
 {{- if .Options}}
-        {{- range $key, $value := .Options }}
-        {{$key}} \
+        {{- range .Options }}
+        {{.}} \
         {{- end}}
+{{- end}}
+{{- if .Body}}
+        --data-binary {{.QuotedBody}} \
 {{- end}}
         -X{{.Method}}
 `
 
 // A separated template for running as it needs to transform the command to an array of string.
-// It splits on newline.
-const runCurlTemplate = `{{.Url}}{{.Path}}{{if .QueryRaw}}?{{.QueryRaw}}{{end}}
+// It splits on newline, so newlines inside a token (e.g. a multi-line body) are written as tokenNewline.
+const runCurlTemplate = `{{.Url}}{{.Path}}{{.UrlQuery}}
 {{- if .Headers}}
-        {{- range $key, $value := .Headers }}
+        {{- range .Headers }}
 -H
-{{$key}}
+{{.}}
         {{- end}}
 {{- end}}
-{{- if .QueryList}}
+{{- if and .QueryList (not .Body)}}
 -G
         {{- range $key := .QueryListKeys }}
 --data-urlencode
-'{{$key}}={{index $.QueryList $key }}'
+{{$key}}={{index $.QueryList $key }}
         {{- end}}
 {{- end}}
 {{- if .Options}}
 {{.OptionsAsToken}}
 {{- end}}
+{{- if .Body}}
+--data-binary
+{{.BodyAsToken}}
+{{- end}}
 -X{{.Method}}`
 
+const tokenNewline = "\x00"
+
+// optionTokenPattern splits an option in the arguments curl gets, keeping quoted values whole.
+var optionTokenPattern = regexp.MustCompile("[^\\s\"']+|\"([^\"]*)\"|'([^']*)'")
+
 type Printer struct {
 	conf    *Configuration
 	writer  io.Writer
 	oneLine bool
+	// as is the client the requests are shown for, curl when empty
+	as string
 }
 
 func (printer *Printer) ShowRequests() {
@@ -86,6 +100,50 @@ func (printer *Printer) ShowEndpoints() {
 	}
 }
 
+func (printer *Printer) ShowEnvironments() {
+	for _, name := range printer.conf.EnvironmentNames() {
+		environment := printer.conf.Environments[name]
+		selected := ""
+		if name == printer.conf.Environment {
+			selected = " (selected)"
+		}
+		fmt.Fprintf(printer.writer, "Environment %v%v:\n", name, selected)
+		if environment.Url != "" {
+			fmt.Fprintf(printer.writer, "        url: %v\n", environment.Url)
+		}
+		variables := make([]string, 0, len(environment.Variables))
+		for k := range environment.Variables {
+			variables = append(variables, k)
+		}
+		sort.Strings(variables)
+		for _, k := range variables {
+			fmt.Fprintf(printer.writer, "        %v: %v\n", k, environment.Variables[k])
+		}
+		for _, header := range environment.Headers {
+			fmt.Fprintf(printer.writer, "        -H '%v'\n", header)
+		}
+		for _, option := range environment.Options {
+			fmt.Fprintf(printer.writer, "        %v\n", option)
+		}
+		fmt.Fprintln(printer.writer, "")
+	}
+}
+
+func (printer *Printer) ShowFlows() {
+	keys := make([]string, 0, len(printer.conf.Flows))
+	for k := range printer.conf.Flows {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, name := range keys {
+		fmt.Fprintf(printer.writer, "Flow %v:\n", name)
+		for i, step := range printer.conf.Flows[name].Steps {
+			fmt.Fprintf(printer.writer, "        %v. %v\n", i+1, step.Request)
+		}
+		fmt.Fprintln(printer.writer, "")
+	}
+}
+
 func (printer *Printer) ShowRequestOrEndpoint(requestName string) {
 	request := printer.conf.Requests[requestName]
 	if request != nil {
@@ -96,15 +154,40 @@ func (printer *Printer) ShowRequestOrEndpoint(requestName string) {
 	endpoint := printer.conf.Endpoints[requestName]
 	if endpoint != nil {
 		printer.showExecutable(endpoint)
+		return
+	}
+
+	for i, name := range printer.conf.MatrixRequests(requestName) {
+		if i > 0 {
+			fmt.Fprintln(printer.writer, "")
+		}
+		printer.showExecutable(printer.conf.Requests[name])
 	}
 }
 
 func (printer *Printer) showExecutable(executable Executable) {
+	if _, ok := showTemplates[printer.as]; ok {
+		printer.showSnippet(executable)
+		return
+	}
 	t := template.Must(template.New("curlTemplate").Parse(printer.getTemplate(executable)))
 	fmt.Fprintf(printer.writer, "Endpoint %v:\n", executable.GetName())
 	t.Execute(printer.writer, executable)
 }
 
+func (printer *Printer) showSnippet(executable Executable) {
+	request, ok := executable.(*Request)
+	if !ok {
+		request = executable.(*Endpoint).asRequest()
+	}
+	snippet, err := renderSnippet(printer.as, request)
+	if err != nil {
+		fmt.Fprintf(printer.writer, "Endpoint %v: %v\n", executable.GetName(), err)
+		return
+	}
+	fmt.Fprint(printer.writer, snippet)
+}
+
 func (printer *Printer) getTemplate(executable Executable) string {
 	if !printer.oneLine {
 		return showCurlTemplate
@@ -124,11 +207,54 @@ func (endpoint *Endpoint) OptionsAsToken() string {
 	return executableOptionsAsToken(endpoint)
 }
 
+func (request *Request) BodyAsToken() string {
+	return executableBodyAsToken(request)
+}
+
+func (endpoint *Endpoint) BodyAsToken() string {
+	return executableBodyAsToken(endpoint)
+}
+
+// UrlQuery is the query written in the url. With a body the query list goes there too, as -G
+// would move the body into the url.
+func (request *Request) UrlQuery() string {
+	return urlQuery(request.QueryRaw, request.QueryListKeys, request.QueryList, request.Body)
+}
+
+func (endpoint *Endpoint) UrlQuery() string {
+	return urlQuery(endpoint.QueryRaw, endpoint.QueryListKeys, endpoint.QueryList, endpoint.Body)
+}
+
+func urlQuery(queryRaw string, keys []string, list map[string]string, body string) string {
+	if queryRaw != "" {
+		return "?" + queryRaw
+	}
+	if body != "" && len(keys) > 0 {
+		return "?" + encodeQueryList(keys, list)
+	}
+	return ""
+}
+
+func (request *Request) QuotedBody() string {
+	return executableQuotedBody(request)
+}
+
+func (endpoint *Endpoint) QuotedBody() string {
+	return executableQuotedBody(endpoint)
+}
+
+func executableBodyAsToken(executable Executable) string {
+	return strings.Replace(executable.GetBody(), "\n", tokenNewline, -1)
+}
+
+func executableQuotedBody(executable Executable) string {
+	return "'" + strings.Replace(executable.GetBody(), "'", "'\\''", -1) + "'"
+}
+
 func executableOptionsAsToken(executable Executable) string {
 	oneLineOptions := ""
-	for option := range executable.GetOptions() {
-		re := regexp.MustCompile("[^\\s\"']+|\"([^\"]*)\"|'([^']*)'")
-		for _, v := range re.FindAllString(option, -1) {
+	for _, option := range executable.GetOptions() {
+		for _, v := range optionTokenPattern.FindAllString(option, -1) {
 			oneLineOptions = oneLineOptions + "\n" + v
 		}
 	}
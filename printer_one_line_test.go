@@ -59,40 +59,40 @@ func TestShowRequestOneLine(t *testing.T) {
 }
 
 var endpoint1OutputOneLine = `Endpoint endpoint1:
-curl 'https://localhost/path1' -H 'Accept: application/vnd.github.v3+json' -H 'Authorization: bearer a12b3c' -H 'Custom: value' -G --data-urlencode 'version=v2' --data-urlencode 'format=json' --data-urlencode 'spec=20' --compress --silent -s -vvv -XGET`
+curl 'https://localhost/path1' -H 'Accept: application/vnd.github.v3+json' -H 'Authorization: bearer a12b3c' -H 'Custom: value' -G --data-urlencode 'version=v2' --data-urlencode 'format={format}' --data-urlencode 'spec={spec}' --compress -vvv --silent -s -XGET`
 
 var request1OutputOneLine = `Endpoint request1:
-curl 'https://localhost/path1' -H 'Accept: application/vnd.github.v3+json' -H 'Authorization: bearer a12b3c' -H 'Custom: value' -G --data-urlencode 'version=v2' --data-urlencode 'format=json' --data-urlencode 'spec=20' --compress --silent -s -vvv -XGET`
+curl 'https://localhost/path1' -H 'Accept: application/vnd.github.v3+json' -H 'Authorization: bearer a12b3c' -H 'Custom: value' -G --data-urlencode 'version=v2' --data-urlencode 'format=json' --data-urlencode 'spec=20' --compress -vvv --silent -s -XGET`
 
 var allEndpointsOutputOneLine = `Endpoint endpoint1:
-curl 'https://localhost/path1' -H 'Accept: application/vnd.github.v3+json' -H 'Authorization: bearer a12b3c' -H 'Custom: value' -G --data-urlencode 'version=v2' --data-urlencode 'format=json' --data-urlencode 'spec=20' --compress --silent -s -vvv -XGET
+curl 'https://localhost/path1' -H 'Accept: application/vnd.github.v3+json' -H 'Authorization: bearer a12b3c' -H 'Custom: value' -G --data-urlencode 'version=v2' --data-urlencode 'format={format}' --data-urlencode 'spec={spec}' --compress -vvv --silent -s -XGET
 
 Endpoint endpoint2:
-curl 'https://localhost/path2/{variable}/something' -H 'Accept: application/vnd.github.v3+json' -H 'Authorization: bearer a12b3c' -H 'Custom: value' --compress --silent -s -vvv -XGET
+curl 'https://localhost/path2/{variable}/something' -H 'Accept: application/vnd.github.v3+json' -H 'Authorization: bearer a12b3c' -H 'Custom: value' --compress -vvv --silent -s -XGET
 
 Endpoint endpoint3:
-curl 'https://localhost/path3' -H 'Accept: application/vnd.github.v3+json' -H 'Authorization: bearer a12b3c' -H 'Content-length: 0' -H 'Custom: value' --compress --silent -s -vvv -XPUT
+curl 'https://localhost/path3' -H 'Accept: application/vnd.github.v3+json' -H 'Authorization: bearer a12b3c' -H 'Custom: value' -H 'Content-length: 0' --compress -vvv --silent -s -XPUT
 
 Endpoint endpoint4:
-curl 'https://localhost/path4/{variable}?name={name}&date={date}&version={version}' -H 'Accept: application/vnd.github.v3+json' -H 'Authorization: bearer a12b3c' -H 'Custom: value' --compress --silent -s -vvv -XPOST
+curl 'https://localhost/path4/{variable}?name={name}&date={date}&version={version}' -H 'Accept: application/vnd.github.v3+json' -H 'Authorization: bearer a12b3c' -H 'Custom: value' --compress -vvv --silent -s -XPOST
 
 Endpoint endpoint5:
-curl 'https://localhost/' -H 'Accept: application/vnd.github.v3+json' -H 'Authorization: bearer a12b3c' -H 'Custom: value' --compress --silent -s -vvv -XDELETE`
+curl 'https://localhost/' -H 'Accept: application/vnd.github.v3+json' -H 'Authorization: bearer a12b3c' -H 'Custom: value' --compress -vvv --silent -s -XDELETE`
 
 var allRequestsOutputOneLine = `Endpoint request1:
-curl 'https://localhost/path1' -H 'Accept: application/vnd.github.v3+json' -H 'Authorization: bearer a12b3c' -H 'Custom: value' -G --data-urlencode 'version=v2' --data-urlencode 'format=json' --data-urlencode 'spec=20' --compress --silent -s -vvv -XGET
+curl 'https://localhost/path1' -H 'Accept: application/vnd.github.v3+json' -H 'Authorization: bearer a12b3c' -H 'Custom: value' -G --data-urlencode 'version=v2' --data-urlencode 'format=json' --data-urlencode 'spec=20' --compress -vvv --silent -s -XGET
 
 Endpoint request2:
-curl 'https://localhost/path2/value/something' -H 'Accept: application/vnd.github.v3+json' -H 'Authorization: bearer a12b3c' -H 'Custom: value' --compress --silent -s -vvv -XGET
+curl 'https://localhost/path2/value/something' -H 'Accept: application/vnd.github.v3+json' -H 'Authorization: bearer a12b3c' -H 'Custom: value' --compress -vvv --silent -s -XGET
 
 Endpoint request3:
-curl 'https://localhost/path3' -H 'Accept: application/vnd.github.v3+json' -H 'Authorization: bearer a12b3c' -H 'Content-length: 0' -H 'Custom: value' --compress --silent -s -vvv -XPUT
+curl 'https://localhost/path3' -H 'Accept: application/vnd.github.v3+json' -H 'Authorization: bearer a12b3c' -H 'Custom: value' -H 'Content-length: 0' --compress -vvv --silent -s -XPUT
 
 Endpoint request4:
-curl 'https://localhost/path4/value?name=gohit&date=today&version=15' -H 'Accept: application/vnd.github.v3+json' -H 'Authorization: bearer a12b3c' -H 'Custom: value' --compress --silent -s -vvv -XPOST
+curl 'https://localhost/path4/value?name=gohit&date=today&version=15' -H 'Accept: application/vnd.github.v3+json' -H 'Authorization: bearer a12b3c' -H 'Custom: value' --compress -vvv --silent -s -XPOST
 
 Endpoint request4_1:
-curl 'https://localhost/path4/value?name=gohit1&date=today1&version=16' -H 'Accept: application/vnd.github.v3+json' -H 'Authorization: bearer a12b3c' -H 'Custom: value' --compress --silent -s -vvv -XPOST
+curl 'https://localhost/path4/value?name=gohit1&date=today1&version=16' -H 'Accept: application/vnd.github.v3+json' -H 'Authorization: bearer a12b3c' -H 'Custom: value' --compress -vvv --silent -s -XPOST
 
 Endpoint request5:
-curl 'https://localhost/' -H 'Accept: application/vnd.github.v3+json' -H 'Authorization: bearer a12b3c' -H 'Custom: value' --compress --silent -s -vvv -XDELETE`
+curl 'https://localhost/' -H 'Accept: application/vnd.github.v3+json' -H 'Authorization: bearer a12b3c' -H 'Custom: value' --compress -vvv --silent -s -XDELETE`
@@ -58,6 +58,25 @@ func TestShowRequest(t *testing.T) {
 	}
 }
 
+func TestShowRequestWithBody(t *testing.T) {
+	conf, _ := NewConfiguration(NewSilentConfigurationReader("_resources/valid", "api-body.yaml"))
+
+	var b bytes.Buffer
+	printer := &Printer{conf: conf, writer: &b}
+
+	printer.ShowRequestOrEndpoint("create_structured")
+
+	if createStructuredOutput != strings.Trim(b.String(), " \n\t") {
+		t.Error("create_structured output doesn't look correct")
+	}
+}
+
+var createStructuredOutput = `Endpoint create_structured:
+curl 'https://localhost/structured' \
+        -H 'Content-type: application/json' \
+        --data-binary '{"name":"{name}","tags":["curl","yaml"]}' \
+        -XPOST`
+
 var endpoint1Output = `Endpoint endpoint1:
 curl 'https://localhost/path1' \
         -H 'Accept: application/vnd.github.v3+json' \
@@ -65,12 +84,12 @@ curl 'https://localhost/path1' \
         -H 'Custom: value' \
         -G \
         --data-urlencode 'version=v2' \
-        --data-urlencode 'format=json' \
-        --data-urlencode 'spec=20' \
+        --data-urlencode 'format={format}' \
+        --data-urlencode 'spec={spec}' \
         --compress \
+        -vvv \
         --silent \
         -s \
-        -vvv \
         -XGET`
 
 var request1Output = `Endpoint request1:
@@ -83,9 +102,9 @@ curl 'https://localhost/path1' \
         --data-urlencode 'format=json' \
         --data-urlencode 'spec=20' \
         --compress \
+        -vvv \
         --silent \
         -s \
-        -vvv \
         -XGET`
 
 var allEndpointsOutput = `Endpoint endpoint1:
@@ -95,12 +114,12 @@ curl 'https://localhost/path1' \
         -H 'Custom: value' \
         -G \
         --data-urlencode 'version=v2' \
-        --data-urlencode 'format=json' \
-        --data-urlencode 'spec=20' \
+        --data-urlencode 'format={format}' \
+        --data-urlencode 'spec={spec}' \
         --compress \
+        -vvv \
         --silent \
         -s \
-        -vvv \
         -XGET
 
 Endpoint endpoint2:
@@ -109,21 +128,21 @@ curl 'https://localhost/path2/{variable}/something' \
         -H 'Authorization: bearer a12b3c' \
         -H 'Custom: value' \
         --compress \
+        -vvv \
         --silent \
         -s \
-        -vvv \
         -XGET
 
 Endpoint endpoint3:
 curl 'https://localhost/path3' \
         -H 'Accept: application/vnd.github.v3+json' \
         -H 'Authorization: bearer a12b3c' \
-        -H 'Content-length: 0' \
         -H 'Custom: value' \
+        -H 'Content-length: 0' \
         --compress \
+        -vvv \
         --silent \
         -s \
-        -vvv \
         -XPUT
 
 Endpoint endpoint4:
@@ -132,9 +151,9 @@ curl 'https://localhost/path4/{variable}?name={name}&date={date}&version={versio
         -H 'Authorization: bearer a12b3c' \
         -H 'Custom: value' \
         --compress \
+        -vvv \
         --silent \
         -s \
-        -vvv \
         -XPOST
 
 Endpoint endpoint5:
@@ -143,9 +162,9 @@ curl 'https://localhost/' \
         -H 'Authorization: bearer a12b3c' \
         -H 'Custom: value' \
         --compress \
+        -vvv \
         --silent \
         -s \
-        -vvv \
         -XDELETE`
 
 var allRequestsOutput = `Endpoint request1:
@@ -158,9 +177,9 @@ curl 'https://localhost/path1' \
         --data-urlencode 'format=json' \
         --data-urlencode 'spec=20' \
         --compress \
+        -vvv \
         --silent \
         -s \
-        -vvv \
         -XGET
 
 Endpoint request2:
@@ -169,21 +188,21 @@ curl 'https://localhost/path2/value/something' \
         -H 'Authorization: bearer a12b3c' \
         -H 'Custom: value' \
         --compress \
+        -vvv \
         --silent \
         -s \
-        -vvv \
         -XGET
 
 Endpoint request3:
 curl 'https://localhost/path3' \
         -H 'Accept: application/vnd.github.v3+json' \
         -H 'Authorization: bearer a12b3c' \
-        -H 'Content-length: 0' \
         -H 'Custom: value' \
+        -H 'Content-length: 0' \
         --compress \
+        -vvv \
         --silent \
         -s \
-        -vvv \
         -XPUT
 
 Endpoint request4:
@@ -192,9 +211,9 @@ curl 'https://localhost/path4/value?name=gohit&date=today&version=15' \
         -H 'Authorization: bearer a12b3c' \
         -H 'Custom: value' \
         --compress \
+        -vvv \
         --silent \
         -s \
-        -vvv \
         -XPOST
 
 Endpoint request4_1:
@@ -203,9 +222,9 @@ curl 'https://localhost/path4/value?name=gohit1&date=today1&version=16' \
         -H 'Authorization: bearer a12b3c' \
         -H 'Custom: value' \
         --compress \
+        -vvv \
         --silent \
         -s \
-        -vvv \
         -XPOST
 
 Endpoint request5:
@@ -214,7 +233,7 @@ curl 'https://localhost/' \
         -H 'Authorization: bearer a12b3c' \
         -H 'Custom: value' \
         --compress \
+        -vvv \
         --silent \
         -s \
-        -vvv \
         -XDELETE`
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RECORDINGS_FILE is where 'gohit record' keeps the responses, relative to the yaml directory.
+const RECORDINGS_FILE = "recordings.json"
+
+// Recording is a call proxied by 'gohit record' and the response it got. Endpoint and
+// Template are those of the endpoint matching the call, if any.
+type Recording struct {
+	Method   string      `json:"method"`
+	Path     string      `json:"path"`
+	Query    string      `json:"query,omitempty"`
+	Endpoint string      `json:"endpoint,omitempty"`
+	Template string      `json:"template,omitempty"`
+	Status   int         `json:"status"`
+	Headers  http.Header `json:"headers,omitempty"`
+	Body     string      `json:"body"`
+}
+
+// RecordingStore keeps the recordings in a json file, one per method, path and query.
+type RecordingStore struct {
+	mutex      sync.Mutex
+	file       string
+	recordings []*Recording
+}
+
+// NewRecordingStore loads the recordings saved in file, if any.
+func NewRecordingStore(file string) (*RecordingStore, error) {
+	store := &RecordingStore{file: file}
+	source, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return store, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(source, &store.recordings); err != nil {
+		return nil, errors.New(fmt.Sprintf("Invalid recordings %v: %v", file, err))
+	}
+	return store, nil
+}
+
+// Add saves a recording, replacing the one with the same method, path and query.
+func (store *RecordingStore) Add(recording *Recording) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	replaced := false
+	for i, existing := range store.recordings {
+		if existing.Method == recording.Method && existing.Path == recording.Path && existing.Query == recording.Query {
+			store.recordings[i] = recording
+			replaced = true
+		}
+	}
+	if !replaced {
+		store.recordings = append(store.recordings, recording)
+	}
+	buf := new(bytes.Buffer)
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(store.recordings); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(store.file, buf.Bytes(), 0644)
+}
+
+// Find looks for the recording of the same call first, then for one of the same endpoint
+// and query, then for any of the same endpoint. It returns a copy, to use without the lock.
+func (store *RecordingStore) Find(method string, path string, query string, endpoint string) *Recording {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	method = strings.ToUpper(method)
+	matches := []func(recording *Recording) bool{
+		func(recording *Recording) bool { return recording.Path == path && recording.Query == query },
+		func(recording *Recording) bool {
+			return endpoint != "" && recording.Endpoint == endpoint && recording.Query == query
+		},
+		func(recording *Recording) bool { return endpoint != "" && recording.Endpoint == endpoint },
+	}
+	for _, matches := range matches {
+		for _, recording := range store.recordings {
+			if recording.Method == method && matches(recording) {
+				found := *recording
+				found.Headers = recording.Headers.Clone()
+				return &found
+			}
+		}
+	}
+	return nil
+}
+
+// normalizedQuery sorts the query parameters so the same call always has the same query.
+func normalizedQuery(rawQuery string) string {
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return rawQuery
+	}
+	return query.Encode()
+}
+
+func (recording *Recording) asResponse() *Response {
+	response := &Response{
+		Status:     fmt.Sprintf("%v %v", recording.Status, http.StatusText(recording.Status)),
+		StatusCode: recording.Status,
+		Headers:    make(http.Header),
+		Body:       []byte(recording.Body),
+	}
+	for name, values := range recording.Headers {
+		response.Headers[name] = values
+	}
+	return response
+}
+
+// NewRecordingProxy forwards the calls to target and records their responses.
+func NewRecordingProxy(conf *Configuration, target *url.URL, store *RecordingStore, logger io.Writer) (http.Handler, error) {
+	routes, err := newMockRoutes(conf)
+	if err != nil {
+		return nil, err
+	}
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	director := proxy.Director
+	proxy.Director = func(r *http.Request) {
+		director(r)
+		r.Host = target.Host
+		// left to the transport, which then returns the body uncompressed
+		r.Header.Del("Accept-Encoding")
+	}
+	var mutex sync.Mutex
+	proxy.ModifyResponse = func(response *http.Response) error {
+		body, err := ioutil.ReadAll(response.Body)
+		response.Body.Close()
+		if err != nil {
+			return err
+		}
+		response.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		path := "/" + strings.TrimPrefix(strings.TrimPrefix(response.Request.URL.Path, strings.TrimSuffix(target.Path, "/")), "/")
+		recording := &Recording{
+			Method:  response.Request.Method,
+			Path:    path,
+			Query:   normalizedQuery(response.Request.URL.RawQuery),
+			Status:  response.StatusCode,
+			Headers: response.Header.Clone(),
+			Body:    string(body),
+		}
+		recording.Headers.Del("Content-Length")
+		matched := "UNMATCHED"
+		if route, _ := matchMockRoute(routes, recording.Method, path); route != nil {
+			recording.Endpoint = route.endpoint.Name
+			recording.Template = route.endpoint.Path
+			matched = route.endpoint.Name
+		}
+		mutex.Lock()
+		fmt.Fprintf(logger, "%v %v %v -> %v %v\n", time.Now().Format("15:04:05"), recording.Method, response.Request.URL.RequestURI(), matched, recording.Status)
+		mutex.Unlock()
+		return store.Add(recording)
+	}
+	return proxy, nil
+}
+
+// ReplayServer answers the calls with the recorded responses.
+type ReplayServer struct {
+	mutex  sync.Mutex
+	routes []*mockRoute
+	store  *RecordingStore
+	logger io.Writer
+}
+
+func NewReplayServer(conf *Configuration, store *RecordingStore, logger io.Writer) (*ReplayServer, error) {
+	routes, err := newMockRoutes(conf)
+	if err != nil {
+		return nil, err
+	}
+	return &ReplayServer{routes: routes, store: store, logger: logger}, nil
+}
+
+func (server *ReplayServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	endpoint := ""
+	if route, _ := matchMockRoute(server.routes, r.Method, r.URL.Path); route != nil {
+		endpoint = route.endpoint.Name
+	}
+	recording := server.store.Find(r.Method, r.URL.Path, normalizedQuery(r.URL.RawQuery), endpoint)
+	if recording == nil {
+		server.log("%v %v -> UNMATCHED", r.Method, r.URL.RequestURI())
+		http.Error(w, fmt.Sprintf("No recording for %v %v", r.Method, r.URL.RequestURI()), http.StatusNotFound)
+		return
+	}
+	for name, values := range recording.Headers {
+		w.Header()[name] = values
+	}
+	w.WriteHeader(recording.Status)
+	io.WriteString(w, recording.Body)
+	server.log("%v %v -> %v %v", r.Method, r.URL.RequestURI(), recording.Path, recording.Status)
+}
+
+func (server *ReplayServer) log(format string, args ...interface{}) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	fmt.Fprintf(server.logger, time.Now().Format("15:04:05")+" "+format+"\n", args...)
+}
+
+// ReplayRunner answers the requests with the recorded responses instead of calling the api.
+type ReplayRunner struct {
+	once  sync.Once
+	file  string
+	store *RecordingStore
+	err   error
+}
+
+func (runner *ReplayRunner) Run(request *Request, command []string) (*Response, error) {
+	runner.once.Do(func() {
+		runner.store, runner.err = NewRecordingStore(runner.file)
+	})
+	if runner.err != nil {
+		return nil, runner.err
+	}
+	target, err := url.Parse(requestUrl(request))
+	if err != nil {
+		return nil, err
+	}
+	// recorded relative to the proxy target, which is the url of the endpoints
+	path := target.Path
+	if base, err := url.Parse(request.Url); err == nil {
+		path = "/" + strings.TrimPrefix(strings.TrimPrefix(path, strings.TrimSuffix(base.Path, "/")), "/")
+	}
+	endpoint, _ := request.Parameters[ENDPOINT].(string)
+	start := time.Now()
+	recording := runner.store.Find(request.Method, path, normalizedQuery(target.RawQuery), endpoint)
+	if recording == nil {
+		return nil, errors.New(fmt.Sprintf("No recording for %v %v, record it with 'gohit record'", request.Method, requestUrl(request)))
+	}
+	response := recording.asResponse()
+	response.Elapsed = time.Since(start)
+	return response, nil
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const recordingConfiguration = `
+url: local/api
+
+endpoints:
+  get_user:
+    path: /users/{id}
+    query:
+      - fields
+
+requests:
+  first_user:
+    endpoint: get_user
+    id: 1
+    fields: name
+  second_user:
+    endpoint: get_user
+    id: 2
+    fields: name
+`
+
+func TestRecordAndReplay(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"path":"` + r.URL.Path + `","query":"` + r.URL.RawQuery + `"}`))
+	}))
+	defer api.Close()
+
+	reader := &MockReader{configurations: make(map[string][]byte)}
+	reader.configurations["test"] = []byte(recordingConfiguration)
+	conf, err := NewConfiguration(reader)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	directory, _ := ioutil.TempDir("", "gohit")
+	defer os.RemoveAll(directory)
+	file := filepath.Join(directory, RECORDINGS_FILE)
+
+	store, _ := NewRecordingStore(file)
+	target, _ := url.Parse(api.URL + "/api")
+	var log bytes.Buffer
+	proxy, err := NewRecordingProxy(conf, target, store, &log)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	server := httptest.NewServer(proxy)
+	for _, path := range []string{"/users/1?fields=name&b=2", "/other"} {
+		response, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		response.Body.Close()
+	}
+	server.Close()
+	if !strings.Contains(log.String(), "GET /api/users/1?fields=name&b=2 -> get_user 200") || !strings.Contains(log.String(), "GET /api/other -> UNMATCHED 200") {
+		t.Errorf("Unexpected log %v", log.String())
+	}
+
+	store, _ = NewRecordingStore(file)
+	recording := store.Find("GET", "/users/1", "b=2&fields=name", "")
+	if recording == nil || recording.Endpoint != "get_user" || recording.Template != "/users/{id}" ||
+		recording.Body != `{"path":"/api/users/1","query":"fields=name&b=2"}` {
+		t.Errorf("Unexpected recording %v", recording)
+		return
+	}
+
+	log.Reset()
+	replay, _ := NewReplayServer(conf, store, &log)
+	server = httptest.NewServer(replay)
+	defer server.Close()
+	for path, status := range map[string]int{"/users/1?b=2&fields=name": 200, "/users/3": 200, "/missing": 404} {
+		response, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		response.Body.Close()
+		if response.StatusCode != status {
+			t.Errorf("Unexpected status for %v %v", path, response.StatusCode)
+		}
+	}
+}
+
+func TestReplayRunner(t *testing.T) {
+	reader := &MockReader{configurations: make(map[string][]byte)}
+	reader.configurations["test"] = []byte(recordingConfiguration)
+	conf, err := NewConfiguration(reader)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	directory, _ := ioutil.TempDir("", "gohit")
+	defer os.RemoveAll(directory)
+	file := filepath.Join(directory, RECORDINGS_FILE)
+	store, _ := NewRecordingStore(file)
+	store.Add(&Recording{Method: "GET", Path: "/users/1", Query: "fields=name", Endpoint: "get_user", Status: 200, Body: "first"})
+	store.Add(&Recording{Method: "GET", Path: "/users/1", Query: "fields=name", Endpoint: "get_user", Status: 200, Body: "replaced"})
+	store.Add(&Recording{Method: "GET", Path: "/users/9", Query: "fields=id", Endpoint: "get_user", Status: 404, Body: "other"})
+
+	executor := NewExecutor(conf, &ReplayRunner{file: file}, &MockVariableReader{})
+	if response, err := executor.ExecuteRequest("first_user", nil); err != nil || string(response.Body) != "replaced" || response.Status != "200 OK" {
+		t.Errorf("Should have replayed the recording of the same call but got %v %v", response, err)
+	}
+	if response, err := executor.ExecuteRequest("second_user", nil); err != nil || string(response.Body) != "replaced" {
+		t.Errorf("Should have replayed a recording of the same endpoint and query but got %v %v", response, err)
+	}
+	if response, err := executor.ExecuteRequest("get_user", []string{"5", "id"}); err != nil || string(response.Body) != "other" {
+		t.Errorf("Should have replayed a recording of the same endpoint but got %v %v", response, err)
+	}
+
+	executor = NewExecutor(conf, &ReplayRunner{file: filepath.Join(directory, "missing.json")}, &MockVariableReader{})
+	if _, err := executor.ExecuteRequest("first_user", nil); err == nil ||
+		err.Error() != "No recording for GET local/api/users/1?fields=name, record it with 'gohit record'" {
+		t.Error("Should have thrown a missing recording error but got ", err)
+	}
+}
+
+func TestReplayServerSlowClient(t *testing.T) {
+	reader := &MockReader{configurations: make(map[string][]byte)}
+	reader.configurations["test"] = []byte(recordingConfiguration)
+	conf, err := NewConfiguration(reader)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	directory, _ := ioutil.TempDir("", "gohit")
+	defer os.RemoveAll(directory)
+	store, _ := NewRecordingStore(filepath.Join(directory, RECORDINGS_FILE))
+	store.Add(&Recording{Method: "GET", Path: "/big", Status: 200, Body: strings.Repeat("a", 32<<20)})
+	store.Add(&Recording{Method: "GET", Path: "/small", Status: 200, Body: "small"})
+
+	var log bytes.Buffer
+	replay, _ := NewReplayServer(conf, store, &log)
+	server := httptest.NewServer(replay)
+	defer server.Close()
+
+	// a client that never reads its response keeps the handler writing
+	slow, err := net.Dial("tcp", server.Listener.Addr().String())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer slow.Close()
+	slow.Write([]byte("GET /big HTTP/1.1\r\nHost: local\r\n\r\n"))
+	time.Sleep(100 * time.Millisecond)
+
+	client := &http.Client{Timeout: 2 * time.Second}
+	response, err := client.Get(server.URL + "/small")
+	if err != nil {
+		t.Error("Should not have waited for the slow client ", err)
+		return
+	}
+	body, _ := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+	if string(body) != "small" {
+		t.Errorf("Unexpected body %v", string(body))
+	}
+}
@@ -0,0 +1,366 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	OUTPUT_RAW    = "raw"
+	OUTPUT_PRETTY = "pretty"
+	OUTPUT_JSON   = "json"
+
+	COLOR_AUTO   = "auto"
+	COLOR_ALWAYS = "always"
+	COLOR_NEVER  = "never"
+)
+
+const (
+	colorReset   = "\x1b[0m"
+	colorRed     = "\x1b[31m"
+	colorGreen   = "\x1b[32m"
+	colorYellow  = "\x1b[33m"
+	colorBlue    = "\x1b[34m"
+	colorMagenta = "\x1b[35m"
+	colorCyan    = "\x1b[36m"
+	colorGrey    = "\x1b[90m"
+)
+
+var (
+	progressMeterHeaderPattern = regexp.MustCompile(`^\s*% Total\s+% Received`)
+	progressMeterPattern       = regexp.MustCompile(`^\s*(Dload\s+Upload|\d+\s+[\d.]+[kMGTP]?\s+\d+(\s|$))`)
+)
+
+// DEFAULT_SHOWN_HEADERS are the response headers shown by the pretty output, '*' shows all of them.
+var DEFAULT_SHOWN_HEADERS = []string{"Content-Type", "Location"}
+
+// ResponsePrinter writes the responses of run. The raw output is the body as returned,
+// pretty adds the status, timing and some headers and indents json and xml bodies,
+// json writes an envelope with the request and response for scripts.
+type ResponsePrinter struct {
+	writer  io.Writer
+	output  string
+	color   bool
+	headers []string
+}
+
+func NewResponsePrinter(writer io.Writer, output string, color string, headers []string) (*ResponsePrinter, error) {
+	if output == "" {
+		output = OUTPUT_RAW
+	}
+	if output != OUTPUT_RAW && output != OUTPUT_PRETTY && output != OUTPUT_JSON {
+		return nil, errors.New(fmt.Sprintf("Invalid output '%v', expected %v, %v or %v", output, OUTPUT_RAW, OUTPUT_PRETTY, OUTPUT_JSON))
+	}
+	printer := &ResponsePrinter{writer: writer, output: output, headers: headers}
+	switch color {
+	case "", COLOR_AUTO:
+		printer.color = isTerminal(writer) && os.Getenv("NO_COLOR") == ""
+	case COLOR_ALWAYS:
+		printer.color = true
+	case COLOR_NEVER:
+	default:
+		return nil, errors.New(fmt.Sprintf("Invalid color '%v', expected %v, %v or %v", color, COLOR_AUTO, COLOR_ALWAYS, COLOR_NEVER))
+	}
+	if len(printer.headers) == 0 {
+		printer.headers = DEFAULT_SHOWN_HEADERS
+	}
+	return printer, nil
+}
+
+func isTerminal(writer io.Writer) bool {
+	file, ok := writer.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// Print writes a response, or the error of a request that couldn't run in the json output.
+func (printer *ResponsePrinter) Print(name string, response *Response, err error) {
+	switch printer.output {
+	case OUTPUT_JSON:
+		printer.printJson(name, response, err)
+	case OUTPUT_PRETTY:
+		if response != nil {
+			printer.printPretty(response)
+		}
+	default:
+		if response != nil {
+			printer.printRaw(response)
+		}
+	}
+}
+
+// PrintTitle separates the responses of several requests, json documents have the name already.
+func (printer *ResponsePrinter) PrintTitle(name string) {
+	if printer.output != OUTPUT_JSON {
+		fmt.Fprintln(printer.writer, printer.paint(colorCyan, "#### "+name+" ####"))
+	}
+}
+
+func (printer *ResponsePrinter) printRaw(response *Response) {
+	fmt.Fprintln(printer.writer, string(response.Body))
+	if len(response.Stderr) > 0 {
+		fmt.Fprintln(printer.writer, "#### Stderr ####")
+		fmt.Fprintln(printer.writer, string(response.Stderr))
+	}
+}
+
+func (printer *ResponsePrinter) printPretty(response *Response) {
+	if response.Request != nil {
+		fmt.Fprintf(printer.writer, "%v %v\n", response.Request.Method, requestUrl(response.Request))
+	}
+	status := response.Status
+	if status == "" {
+		status = "no status"
+	}
+	fmt.Fprintf(printer.writer, "%v %v\n", printer.paint(statusColor(response.StatusCode), status),
+		printer.paint(colorGrey, fmt.Sprintf("(%v, %v)", response.Elapsed.Round(time.Millisecond), formatSize(len(response.Body)))))
+	names := printer.headers
+	if len(names) == 1 && names[0] == "*" {
+		names = sortedHeaderNames(response.Headers)
+	}
+	for _, name := range names {
+		for _, value := range response.Headers[http.CanonicalHeaderKey(name)] {
+			fmt.Fprintf(printer.writer, "%v: %v\n", printer.paint(colorCyan, http.CanonicalHeaderKey(name)), value)
+		}
+	}
+	fmt.Fprintln(printer.writer)
+
+	contentType := response.Headers.Get("Content-Type")
+	if indented, ok := indentJson(response.Body, contentType); ok {
+		if printer.color {
+			indented = colorJson(indented)
+		}
+		fmt.Fprintln(printer.writer, indented)
+	} else if indented, ok := indentXml(response.Body, contentType); ok {
+		fmt.Fprintln(printer.writer, indented)
+	} else {
+		fmt.Fprintln(printer.writer, string(response.Body))
+	}
+
+	if stderr := withoutProgressMeter(response.Stderr); stderr != "" {
+		fmt.Fprintln(printer.writer, printer.paint(colorGrey, "#### Stderr ####"))
+		fmt.Fprintln(printer.writer, printer.paint(colorGrey, stderr))
+	}
+}
+
+// withoutProgressMeter removes the progress meter curl writes to stderr when not silent: the
+// '% Total' header and the lines of updates right after it. Any other line is kept.
+func withoutProgressMeter(stderr []byte) string {
+	var lines []string
+	meter := false
+	for _, line := range strings.Split(string(stderr), "\n") {
+		if progressMeterHeaderPattern.MatchString(line) {
+			meter = true
+		} else if !meter || !isProgressMeterLine(line) {
+			meter = false
+			lines = append(lines, line)
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// isProgressMeterLine tells if every update of a line, separated by '\r', is a progress meter one.
+func isProgressMeterLine(line string) bool {
+	for _, update := range strings.Split(line, "\r") {
+		if strings.TrimSpace(update) != "" && !progressMeterPattern.MatchString(update) {
+			return false
+		}
+	}
+	return true
+}
+
+type jsonEnvelope struct {
+	Request   *jsonEnvelopeRequest `json:"request"`
+	Status    int                  `json:"status,omitempty"`
+	Reason    string               `json:"status_line,omitempty"`
+	Headers   http.Header          `json:"headers,omitempty"`
+	Body      interface{}          `json:"body,omitempty"`
+	Size      int                  `json:"size"`
+	ElapsedMs float64              `json:"elapsed_ms"`
+	Stderr    string               `json:"stderr,omitempty"`
+	Error     string               `json:"error,omitempty"`
+}
+
+type jsonEnvelopeRequest struct {
+	Name    string   `json:"name"`
+	Method  string   `json:"method,omitempty"`
+	Url     string   `json:"url,omitempty"`
+	Headers []string `json:"headers,omitempty"`
+	Body    string   `json:"body,omitempty"`
+}
+
+func (printer *ResponsePrinter) printJson(name string, response *Response, err error) {
+	encoder := json.NewEncoder(printer.writer)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(newJsonEnvelope(name, response, err))
+}
+
+// newJsonEnvelope keeps json bodies as json, other bodies are strings.
+func newJsonEnvelope(name string, response *Response, err error) *jsonEnvelope {
+	envelope := &jsonEnvelope{Request: &jsonEnvelopeRequest{Name: name}}
+	if err != nil {
+		envelope.Error = err.Error()
+	}
+	if response == nil {
+		return envelope
+	}
+	if request := response.Request; request != nil {
+		envelope.Request.Method = request.Method
+		envelope.Request.Url = requestUrl(request)
+		envelope.Request.Headers = request.Headers
+		envelope.Request.Body = request.Body
+	}
+	envelope.Status = response.StatusCode
+	envelope.Reason = response.Status
+	envelope.Headers = response.Headers
+	envelope.Size = len(response.Body)
+	envelope.ElapsedMs = float64(response.Elapsed.Microseconds()) / 1000
+	envelope.Stderr = withoutProgressMeter(response.Stderr)
+	var body interface{}
+	if decodeJson(response.Body, &body) == nil {
+		envelope.Body = body
+	} else if len(response.Body) > 0 {
+		envelope.Body = string(response.Body)
+	}
+	return envelope
+}
+
+func (printer *ResponsePrinter) paint(color string, text string) string {
+	if !printer.color {
+		return text
+	}
+	return color + text + colorReset
+}
+
+func statusColor(statusCode int) string {
+	switch {
+	case statusCode >= 400:
+		return colorRed
+	case statusCode >= 300:
+		return colorYellow
+	case statusCode >= 200:
+		return colorGreen
+	}
+	return colorGrey
+}
+
+func formatSize(size int) string {
+	switch {
+	case size >= 1024*1024:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1024*1024))
+	case size >= 1024:
+		return fmt.Sprintf("%.1f KB", float64(size)/1024)
+	}
+	return fmt.Sprintf("%v B", size)
+}
+
+func indentJson(body []byte, contentType string) (string, bool) {
+	trimmed := bytes.TrimSpace(body)
+	if !strings.Contains(contentType, "json") && (len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[')) {
+		return "", false
+	}
+	var indented bytes.Buffer
+	if json.Indent(&indented, trimmed, "", "  ") != nil {
+		return "", false
+	}
+	return indented.String(), true
+}
+
+func indentXml(body []byte, contentType string) (string, bool) {
+	trimmed := bytes.TrimSpace(body)
+	if !strings.Contains(contentType, "xml") && !bytes.HasPrefix(trimmed, []byte("<?xml")) {
+		return "", false
+	}
+	decoder := xml.NewDecoder(bytes.NewReader(trimmed))
+	var indented bytes.Buffer
+	encoder := xml.NewEncoder(&indented)
+	encoder.Indent("", "  ")
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return "", false
+		}
+		if data, ok := token.(xml.CharData); ok {
+			if len(bytes.TrimSpace(data)) == 0 {
+				continue
+			}
+		}
+		if err := encoder.EncodeToken(xml.CopyToken(token)); err != nil {
+			return "", false
+		}
+	}
+	if err := encoder.Flush(); err != nil {
+		return "", false
+	}
+	return indented.String(), true
+}
+
+// colorJson colours indented json: keys blue, strings green, numbers yellow, booleans magenta and null grey.
+func colorJson(indented string) string {
+	var colored strings.Builder
+	for i := 0; i < len(indented); i++ {
+		c := indented[i]
+		switch {
+		case c == '"':
+			end := i + 1
+			for ; end < len(indented) && indented[end] != '"'; end++ {
+				if indented[end] == '\\' {
+					end++
+				}
+			}
+			token := indented[i : end+1]
+			color := colorGreen
+			if end+1 < len(indented) && indented[end+1] == ':' {
+				color = colorBlue
+			}
+			colored.WriteString(color + token + colorReset)
+			i = end
+		case c == '-' || (c >= '0' && c <= '9'):
+			end := i
+			for end < len(indented) && strings.IndexByte("+-.eE0123456789", indented[end]) >= 0 {
+				end++
+			}
+			colored.WriteString(colorYellow + indented[i:end] + colorReset)
+			i = end - 1
+		case strings.HasPrefix(indented[i:], "true") || strings.HasPrefix(indented[i:], "false"):
+			end := i + 4
+			if c == 'f' {
+				end++
+			}
+			colored.WriteString(colorMagenta + indented[i:end] + colorReset)
+			i = end - 1
+		case strings.HasPrefix(indented[i:], "null"):
+			colored.WriteString(colorGrey + "null" + colorReset)
+			i += 3
+		default:
+			colored.WriteByte(c)
+		}
+	}
+	return colored.String()
+}
+
+// sortedHeaderNames is used to show all the headers, asked for with '*'.
+func sortedHeaderNames(headers http.Header) []string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func prettyResponse(body string, contentType string) *Response {
+	return &Response{
+		Request:    &Request{Name: "test", Method: "GET", Url: "http://localhost", Path: "/items", QueryList: map[string]string{}},
+		Status:     "200 OK",
+		StatusCode: 200,
+		Headers:    http.Header{"Content-Type": []string{contentType}, "Etag": []string{"123"}},
+		Body:       []byte(body),
+		Elapsed:    1500 * time.Microsecond,
+	}
+}
+
+func TestPrintRaw(t *testing.T) {
+	var b bytes.Buffer
+	printer, _ := NewResponsePrinter(&b, "", COLOR_AUTO, nil)
+	response := prettyResponse(`{"a":1}`, "application/json")
+	response.Stderr = []byte("warning")
+	printer.Print("test", response, nil)
+
+	if b.String() != "{\"a\":1}\n#### Stderr ####\nwarning\n" {
+		t.Errorf("Unexpected raw output %q", b.String())
+	}
+}
+
+func TestPrintPrettyJson(t *testing.T) {
+	var b bytes.Buffer
+	printer, _ := NewResponsePrinter(&b, OUTPUT_PRETTY, COLOR_NEVER, []string{"etag"})
+	response := prettyResponse(`{"a":[1,"b"]}`, "application/json")
+	response.Stderr = []byte("  % Total    % Received\n                                 Dload  Upload\n\r100    41  100")
+	printer.Print("test", response, nil)
+
+	expected := `GET http://localhost/items
+200 OK (2ms, 13 B)
+Etag: 123
+
+{
+  "a": [
+    1,
+    "b"
+  ]
+}
+`
+	if b.String() != expected {
+		t.Errorf("Unexpected pretty output\n%v", b.String())
+	}
+}
+
+func TestWithoutProgressMeter(t *testing.T) {
+	stderr := "401 0 unauthorized request\n" +
+		"  % Total    % Received % Xferd  Average Speed   Time    Time     Time  Current\n" +
+		"                                 Dload  Upload   Total   Spent    Left  Speed\n" +
+		"\r  0     0    0     0    0     0      0      0 --:--:-- --:--:-- --:--:--     0\r100  1234  100  1234    0     0  61700      0 --:--:-- --:--:-- --:--:-- 61700\n" +
+		"12 34 items skipped\n" +
+		"curl: (7) Failed to connect"
+	expected := "401 0 unauthorized request\n12 34 items skipped\ncurl: (7) Failed to connect"
+	if output := withoutProgressMeter([]byte(stderr)); output != expected {
+		t.Errorf("Should have removed only the progress meter but got %q", output)
+	}
+	if output := withoutProgressMeter([]byte("100 200 ok\n  Dload Upload")); output != "100 200 ok\n  Dload Upload" {
+		t.Errorf("Should have kept the lines without a progress meter but got %q", output)
+	}
+}
+
+func TestPrintPrettyXml(t *testing.T) {
+	var b bytes.Buffer
+	printer, _ := NewResponsePrinter(&b, OUTPUT_PRETTY, COLOR_NEVER, nil)
+	printer.Print("test", prettyResponse(`<a><b x="1">text</b></a>`, "application/xml"), nil)
+
+	expected := `GET http://localhost/items
+200 OK (2ms, 24 B)
+Content-Type: application/xml
+
+<a>
+  <b x="1">text</b>
+</a>
+`
+	if b.String() != expected {
+		t.Errorf("Unexpected pretty output\n%v", b.String())
+	}
+}
+
+func TestPrintJson(t *testing.T) {
+	var b bytes.Buffer
+	printer, _ := NewResponsePrinter(&b, OUTPUT_JSON, COLOR_ALWAYS, nil)
+	printer.Print("test", prettyResponse(`{"a":1}`, "application/json"), nil)
+
+	envelope := make(map[string]interface{})
+	if err := json.Unmarshal(b.Bytes(), &envelope); err != nil {
+		t.Error(err)
+		return
+	}
+	if envelope["status"] != 200.0 || envelope["elapsed_ms"] != 1.5 || envelope["size"] != 7.0 {
+		t.Errorf("Unexpected envelope %v", envelope)
+	}
+	if envelope["body"].(map[string]interface{})["a"] != 1.0 {
+		t.Errorf("Json body should be kept as json %v", envelope["body"])
+	}
+	if envelope["request"].(map[string]interface{})["url"] != "http://localhost/items" {
+		t.Errorf("Unexpected request %v", envelope["request"])
+	}
+
+	b.Reset()
+	printer.Print("test", prettyResponse(`{"id":12345678901234567890,"ratio":0.10}`, "application/json"), nil)
+	if !strings.Contains(b.String(), `"id": 12345678901234567890,`) || !strings.Contains(b.String(), `"ratio": 0.10`) {
+		t.Errorf("Should have kept the numbers as written %v", b.String())
+	}
+
+	b.Reset()
+	printer.Print("missing", nil, errors.New("Could not find request/endpoint missing"))
+	if b.String() != "{\n  \"request\": {\n    \"name\": \"missing\"\n  },\n  \"size\": 0,\n  \"elapsed_ms\": 0,\n  \"error\": \"Could not find request/endpoint missing\"\n}\n" {
+		t.Errorf("Unexpected error envelope %v", b.String())
+	}
+}
+
+func TestColorJson(t *testing.T) {
+	colored := colorJson(`{"a": "b", "c": -1.5, "d": [true, null]}`)
+	expected := "{" + colorBlue + `"a"` + colorReset + ": " + colorGreen + `"b"` + colorReset + ", " +
+		colorBlue + `"c"` + colorReset + ": " + colorYellow + "-1.5" + colorReset + ", " +
+		colorBlue + `"d"` + colorReset + ": [" + colorMagenta + "true" + colorReset + ", " + colorGrey + "null" + colorReset + "]}"
+	if colored != expected {
+		t.Errorf("Unexpected colors %q", colored)
+	}
+}
+
+func TestInvalidResponsePrinter(t *testing.T) {
+	if _, err := NewResponsePrinter(&bytes.Buffer{}, "yaml", COLOR_AUTO, nil); err == nil {
+		t.Error("Should fail on invalid output")
+	}
+	if _, err := NewResponsePrinter(&bytes.Buffer{}, OUTPUT_PRETTY, "sometimes", nil); err == nil {
+		t.Error("Should fail on invalid color")
+	}
+	printer, _ := NewResponsePrinter(&bytes.Buffer{}, OUTPUT_PRETTY, COLOR_AUTO, nil)
+	if printer.color {
+		t.Error("Buffers aren't terminals")
+	}
+}
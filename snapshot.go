@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// SNAPSHOT_DIRECTORY is where the snapshots are kept, relative to the yaml directory.
+	SNAPSHOT_DIRECTORY = "snapshots"
+	// SNAPSHOT_IGNORED replaces the values of the ignored paths in the snapshots.
+	SNAPSHOT_IGNORED = "<ignored>"
+)
+
+// Snapshotter records the responses of requests as golden files and checks them for drift.
+type Snapshotter struct {
+	executor  *Executor
+	writer    io.Writer
+	directory string
+}
+
+// snapshot is the normalized response of a request: json bodies are indented with sorted keys
+// and the ignored paths masked, other bodies are kept as text.
+type snapshot struct {
+	Status      int         `json:"status"`
+	ContentType string      `json:"content_type,omitempty"`
+	Json        interface{} `json:"json,omitempty"`
+	Text        string      `json:"text,omitempty"`
+}
+
+func NewSnapshotter(executor *Executor, writer io.Writer, directory string) *Snapshotter {
+	return &Snapshotter{executor: executor, writer: writer, directory: directory}
+}
+
+// Update runs the given requests, or every request when none is given, and saves their
+// responses as snapshots. It returns how many of them couldn't run.
+func (snapshotter *Snapshotter) Update(requestNames []string) (int, error) {
+	requestNames, err := snapshotter.requestNames(requestNames)
+	if err != nil {
+		return 0, err
+	}
+	failed := 0
+	for _, name := range requestNames {
+		response, err := snapshotter.executor.ExecuteRequest(name, nil)
+		if err != nil {
+			fmt.Fprintf(snapshotter.writer, "FAIL %v\n    %v\n", name, err)
+			failed++
+			continue
+		}
+		if err := snapshotter.save(name, newSnapshot(response, snapshotter.executor.conf.Requests[name].Ignore)); err != nil {
+			return failed, err
+		}
+		fmt.Fprintf(snapshotter.writer, "UPDATED %v\n", name)
+	}
+	fmt.Fprintf(snapshotter.writer, "\n%v updated, %v failed\n", len(requestNames)-failed, failed)
+	return failed, nil
+}
+
+// Check runs the given requests, or every request when none is given, and compares their
+// responses with the snapshots. It returns how many of them drifted, failed or have no snapshot.
+func (snapshotter *Snapshotter) Check(requestNames []string) (int, error) {
+	requestNames, err := snapshotter.requestNames(requestNames)
+	if err != nil {
+		return 0, err
+	}
+	failed := 0
+	for _, name := range requestNames {
+		if !snapshotter.checkRequest(name) {
+			failed++
+		}
+	}
+	fmt.Fprintf(snapshotter.writer, "\n%v passed, %v failed\n", len(requestNames)-failed, failed)
+	return failed, nil
+}
+
+func (snapshotter *Snapshotter) checkRequest(name string) bool {
+	saved, err := snapshotter.load(name)
+	if err != nil {
+		fmt.Fprintf(snapshotter.writer, "FAIL %v\n    %v\n", name, err)
+		return false
+	}
+	response, err := snapshotter.executor.ExecuteRequest(name, nil)
+	if err != nil {
+		fmt.Fprintf(snapshotter.writer, "FAIL %v\n    %v\n", name, err)
+		return false
+	}
+
+	differences := DiffResponses(saved.asResponse(), response, snapshotter.executor.conf.Requests[name].Ignore)
+	result := "PASS"
+	if len(differences) > 0 {
+		result = "FAIL"
+	}
+	fmt.Fprintf(snapshotter.writer, "%v %v (%v)\n", result, name, response.Elapsed.Round(time.Millisecond))
+	for _, difference := range differences {
+		fmt.Fprintf(snapshotter.writer, "    %v\n", difference)
+	}
+	return len(differences) == 0
+}
+
+func (snapshotter *Snapshotter) requestNames(requestNames []string) ([]string, error) {
+	for _, name := range requestNames {
+		if snapshotter.executor.conf.Requests[name] == nil {
+			return nil, errors.New(fmt.Sprint("Could not find request ", name))
+		}
+	}
+	if len(requestNames) > 0 {
+		return requestNames, nil
+	}
+	for name := range snapshotter.executor.conf.Requests {
+		requestNames = append(requestNames, name)
+	}
+	if len(requestNames) == 0 {
+		return nil, errors.New("No requests found")
+	}
+	sort.Strings(requestNames)
+	return requestNames, nil
+}
+
+func (snapshotter *Snapshotter) file(name string) string {
+	return snapshotFile(snapshotter.directory, name)
+}
+
+// snapshotFile escapes the whole name so every request has its own file, '%', the path
+// separators and the characters some systems don't allow in file names becoming %XX.
+func snapshotFile(directory string, name string) string {
+	escaped := new(strings.Builder)
+	for _, b := range []byte(name) {
+		if b < 0x20 || strings.IndexByte(`%/\:*?"<>|`, b) >= 0 {
+			fmt.Fprintf(escaped, "%%%02X", b)
+		} else {
+			escaped.WriteByte(b)
+		}
+	}
+	return filepath.Join(directory, escaped.String()+".json")
+}
+
+func (snapshotter *Snapshotter) save(name string, saved *snapshot) error {
+	buf := new(bytes.Buffer)
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(saved); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(snapshotter.directory, 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(snapshotter.file(name), buf.Bytes(), 0644)
+}
+
+func (snapshotter *Snapshotter) load(name string) (*snapshot, error) {
+	saved, err := readSnapshot(snapshotter.directory, name)
+	if os.IsNotExist(err) {
+		return nil, errors.New("Missing snapshot, run 'gohit snapshot update " + name + "'")
+	}
+	return saved, err
+}
+
+// readSnapshot returns the snapshot of a request, or an error satisfying os.IsNotExist when there is none.
+func readSnapshot(directory string, name string) (*snapshot, error) {
+	source, err := ioutil.ReadFile(snapshotFile(directory, name))
+	if err != nil {
+		return nil, err
+	}
+	saved := &snapshot{}
+	if err := decodeJson(source, saved); err != nil {
+		return nil, errors.New(fmt.Sprintf("Invalid snapshot %v: %v", snapshotFile(directory, name), err))
+	}
+	return saved, nil
+}
+
+func newSnapshot(response *Response, ignore []string) *snapshot {
+	saved := &snapshot{Status: response.StatusCode, ContentType: response.Headers.Get("Content-Type")}
+	var body interface{}
+	if decodeJson(response.Body, &body) == nil && body != nil {
+		for _, path := range ignore {
+			body = maskJsonPath(body, splitJsonPath(path))
+		}
+		saved.Json = body
+	} else {
+		saved.Text = string(response.Body)
+	}
+	return saved
+}
+
+// maskJsonPath replaces the values at a path with SNAPSHOT_IGNORED, '*' matching any key or index.
+func maskJsonPath(document interface{}, keys []string) interface{} {
+	if len(keys) == 0 {
+		return SNAPSHOT_IGNORED
+	}
+	switch node := document.(type) {
+	case map[string]interface{}:
+		for name, value := range node {
+			if keys[0] == "*" || keys[0] == name {
+				node[name] = maskJsonPath(value, keys[1:])
+			}
+		}
+	case []interface{}:
+		for i := range node {
+			if keys[0] == "*" || keys[0] == strconv.Itoa(i) {
+				node[i] = maskJsonPath(node[i], keys[1:])
+			}
+		}
+	}
+	return document
+}
+
+// asResponse returns the response the snapshot was made of, without the ignored values.
+func (saved *snapshot) asResponse() *Response {
+	response := &Response{StatusCode: saved.Status, Headers: make(http.Header), Body: []byte(saved.Text)}
+	if saved.ContentType != "" {
+		response.Headers.Set("Content-Type", saved.ContentType)
+	}
+	if saved.Json != nil {
+		buf := new(bytes.Buffer)
+		encoder := json.NewEncoder(buf)
+		encoder.SetEscapeHTML(false)
+		encoder.Encode(saved.Json)
+		response.Body = bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+	}
+	return response
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSnapshots(t *testing.T) {
+	reader := &MockReader{configurations: make(map[string][]byte)}
+	reader.configurations["test"] = []byte(
+		`
+url: local
+
+endpoints:
+  test:
+    path: /test
+
+requests:
+  user:
+    endpoint: test
+    ignore:
+      - updated_at
+      - items[*].id
+  text:
+    endpoint: test
+`)
+
+	conf, err := NewConfiguration(reader)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	directory, _ := ioutil.TempDir("", "gohit")
+	defer os.RemoveAll(directory)
+	runner := &MockResponseRunner{responses: map[string]*Response{
+		"user": {StatusCode: 200, Body: []byte(`{"name": "a", "updated_at": "10:00", "items": [{"id": 1, "b": 2}]}`)},
+		"text": {StatusCode: 200, Body: []byte("hello")},
+	}}
+	var b bytes.Buffer
+	snapshotter := NewSnapshotter(NewExecutor(conf, runner, &MockVariableReader{}), &b, filepath.Join(directory, SNAPSHOT_DIRECTORY))
+
+	if failed, err := snapshotter.Update(nil); err != nil || failed != 0 {
+		t.Errorf("Should have updated the snapshots but got %v %v", failed, err)
+	}
+	saved, _ := ioutil.ReadFile(filepath.Join(directory, SNAPSHOT_DIRECTORY, "user.json"))
+	expected := `{
+  "status": 200,
+  "json": {
+    "items": [
+      {
+        "b": 2,
+        "id": "<ignored>"
+      }
+    ],
+    "name": "a",
+    "updated_at": "<ignored>"
+  }
+}
+`
+	if string(saved) != expected {
+		t.Errorf("Unexpected snapshot %v", string(saved))
+	}
+
+	runner.responses["user"] = &Response{StatusCode: 200, Body: []byte(`{"name": "a", "updated_at": "11:00", "items": [{"id": 2, "b": 2}]}`)}
+	b.Reset()
+	if failed, err := snapshotter.Check(nil); err != nil || failed != 0 {
+		t.Errorf("Should have passed ignoring the changed paths but got %v %v\n%v", failed, err, b.String())
+	}
+
+	runner.responses["text"] = &Response{StatusCode: 500, Body: []byte("error")}
+	b.Reset()
+	if failed, err := snapshotter.Check([]string{"text"}); err != nil || failed != 1 {
+		t.Errorf("Should have failed on drift but got %v %v", failed, err)
+	}
+	if !strings.Contains(b.String(), "FAIL text") || !strings.Contains(b.String(), `~ status: 200 -> 500`) ||
+		!strings.Contains(b.String(), `~ body line 1: "hello" -> "error"`) {
+		t.Errorf("Unexpected check output %v", b.String())
+	}
+}
+
+func TestMissingSnapshot(t *testing.T) {
+	conf, _ := NewConfiguration(NewSilentConfigurationReader("_resources/valid", "api-requests.yaml"))
+	directory, _ := ioutil.TempDir("", "gohit")
+	defer os.RemoveAll(directory)
+	var b bytes.Buffer
+	snapshotter := NewSnapshotter(NewExecutor(conf, &MockResponseRunner{}, &MockVariableReader{}), &b, directory)
+
+	if _, err := snapshotter.Check([]string{"not-found"}); err == nil || err.Error() != "Could not find request not-found" {
+		t.Error("Should throw a not found error but got ", err)
+	}
+	if failed, err := snapshotter.Check([]string{"request1"}); err != nil || failed != 1 ||
+		!strings.Contains(b.String(), "Missing snapshot, run 'gohit snapshot update request1'") {
+		t.Errorf("Should have failed without snapshot but got %v %v", failed, b.String())
+	}
+}
+
+func TestSnapshotFiles(t *testing.T) {
+	reader := &MockReader{configurations: make(map[string][]byte)}
+	reader.configurations["test"] = []byte(`
+url: local
+
+endpoints:
+  test:
+    path: /test
+
+requests:
+  v1/user:
+    endpoint: test
+  v2/user:
+    endpoint: test
+`)
+	conf, err := NewConfiguration(reader)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	directory, _ := ioutil.TempDir("", "gohit")
+	defer os.RemoveAll(directory)
+	runner := &MockResponseRunner{responses: map[string]*Response{
+		"v1/user": {StatusCode: 200, Body: []byte(`{"id": 12345678901234567}`)},
+		"v2/user": {StatusCode: 200, Body: []byte(`{"id": 2}`)},
+	}}
+	var b bytes.Buffer
+	snapshotter := NewSnapshotter(NewExecutor(conf, runner, &MockVariableReader{}), &b, directory)
+
+	if failed, err := snapshotter.Update(nil); err != nil || failed != 0 {
+		t.Errorf("Should have updated the snapshots but got %v %v", failed, err)
+	}
+	saved, _ := ioutil.ReadFile(filepath.Join(directory, "v1%2Fuser.json"))
+	if !strings.Contains(string(saved), `"id": 12345678901234567`) {
+		t.Errorf("Should have kept the id as written but got %v", string(saved))
+	}
+	if saved, _ := ioutil.ReadFile(filepath.Join(directory, "v2%2Fuser.json")); !strings.Contains(string(saved), `"id": 2`) {
+		t.Errorf("Should have saved each request in its own file but got %v", string(saved))
+	}
+
+	runner.responses["v1/user"] = &Response{StatusCode: 200, Body: []byte(`{"id": 12345678901234568}`)}
+	runner.responses["v2/user"] = &Response{StatusCode: 200, Body: []byte(`{"id": 2.0}`)}
+	b.Reset()
+	if failed, err := snapshotter.Check(nil); err != nil || failed != 1 ||
+		!strings.Contains(b.String(), "FAIL v1/user") || !strings.Contains(b.String(), "~ $.id: 12345678901234567 -> 12345678901234568") ||
+		!strings.Contains(b.String(), "PASS v2/user") {
+		t.Errorf("Should have failed only on the changed id but got %v %v\n%v", failed, err, b.String())
+	}
+
+	for name, file := range map[string]string{"../x": "..%2Fx.json", "a%2Fb": "a%252Fb.json", `c:\d`: "c%3A%5Cd.json"} {
+		if snapshotFile("", name) != file {
+			t.Errorf("Unexpected file for %v: %v", name, snapshotFile("", name))
+		}
+	}
+}
@@ -0,0 +1,324 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// snippet is the view of a request or endpoint used by the show --as templates.
+type snippet struct {
+	Method   string
+	Url      string
+	Headers  []snippetHeader
+	Body     string
+	User     string
+	Password string
+	Insecure bool
+	Follow   bool
+	Timeout  float64
+	// curl options with no translation, shown as a comment
+	Ignored []string
+}
+
+type snippetHeader struct {
+	Name  string
+	Value string
+}
+
+const httpieTemplate = `{{- if .Ignored}}# curl options not translated: {{join .Ignored " "}}
+{{end -}}
+http{{if .Insecure}} --verify=no{{end}}{{if .Follow}} --follow{{end}}{{if .User}} --auth {{shell (printf "%v:%v" .User .Password)}}{{end}}{{if .Timeout}} --timeout={{.Timeout}}{{end}} {{.Method}} {{shell .Url}}
+{{- range .Headers}} \
+    {{shell (printf "%v:%v" .Name .Value)}}
+{{- end}}
+{{- if .Body}} \
+    --raw {{shell .Body}}
+{{- end}}
+`
+
+const wgetTemplate = `{{- if .Ignored}}# curl options not translated: {{join .Ignored " "}}
+{{end -}}
+wget --quiet --output-document=- --method={{.Method}}
+{{- range .Headers}} \
+    --header={{shell (printf "%v: %v" .Name .Value)}}
+{{- end}}
+{{- if .Body}} \
+    --body-data={{shell .Body}}
+{{- end}}
+{{- if .User}} \
+    --user={{shell .User}} --password={{shell .Password}}
+{{- end}}
+{{- if .Insecure}} \
+    --no-check-certificate
+{{- end}}
+{{- if not .Follow}} \
+    --max-redirect=0
+{{- end}}
+{{- if .Timeout}} \
+    --timeout={{.Timeout}}
+{{- end}} \
+    {{shell .Url}}
+`
+
+const powershellTemplate = `{{- if .Ignored}}# curl options not translated: {{join .Ignored " "}}
+{{end -}}
+$headers = @{
+{{- range .Headers}}{{if ne (lower .Name) "content-type"}}
+    {{powershell .Name}} = {{powershell .Value}}
+{{- end}}{{end}}
+{{- if .User}}
+    'Authorization' = {{powershell (basicAuth .User .Password)}}
+{{- end}}
+}
+Invoke-RestMethod -Method {{.Method}} -Uri {{powershell .Url}} -Headers $headers
+{{- range .Headers}}{{if eq (lower .Name) "content-type"}} -ContentType {{powershell .Value}}{{end}}{{end}}
+{{- if .Body}} -Body {{powershell .Body}}{{end}}
+{{- if .Insecure}} -SkipCertificateCheck{{end}}
+{{- if not .Follow}} -MaximumRedirection 0{{end}}
+{{- if .Timeout}} -TimeoutSec {{ceil .Timeout}}{{end}}
+`
+
+const pythonRequestsTemplate = `import requests
+{{if .Ignored}}
+# curl options not translated: {{join .Ignored " "}}
+{{end}}
+response = requests.request(
+    {{quote .Method}},
+    {{quote .Url}},
+{{- if .Headers}}
+    headers={
+{{- range .Headers}}
+        {{quote .Name}}: {{quote .Value}},
+{{- end}}
+    },
+{{- end}}
+{{- if .Body}}
+    data={{quote .Body}},
+{{- end}}
+{{- if .User}}
+    auth=({{quote .User}}, {{quote .Password}}),
+{{- end}}
+{{- if .Insecure}}
+    verify=False,
+{{- end}}
+{{- if not .Follow}}
+    allow_redirects=False,
+{{- end}}
+{{- if .Timeout}}
+    timeout={{.Timeout}},
+{{- end}}
+)
+print(response.text)
+`
+
+const goNetHttpTemplate = `package main
+
+import (
+{{- if .Insecure}}
+	"crypto/tls"
+{{- end}}
+	"fmt"
+	"io/ioutil"
+	"net/http"
+{{- if .Body}}
+	"strings"
+{{- end}}
+{{- if .Timeout}}
+	"time"
+{{- end}}
+)
+
+func main() {
+{{- if .Ignored}}
+	// curl options not translated: {{join .Ignored " "}}
+{{- end}}
+{{- if .Body}}
+	body := strings.NewReader({{goQuote .Body}})
+	request, err := http.NewRequest({{goQuote .Method}}, {{goQuote .Url}}, body)
+{{- else}}
+	request, err := http.NewRequest({{goQuote .Method}}, {{goQuote .Url}}, nil)
+{{- end}}
+	if err != nil {
+		panic(err)
+	}
+{{- range .Headers}}
+	request.Header.Add({{goQuote .Name}}, {{goQuote .Value}})
+{{- end}}
+{{- if .User}}
+	request.SetBasicAuth({{goQuote .User}}, {{goQuote .Password}})
+{{- end}}
+
+	client := &http.Client{}
+{{- if .Insecure}}
+	client.Transport = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+{{- end}}
+{{- if .Timeout}}
+	client.Timeout = time.Duration({{.Timeout}} * float64(time.Second))
+{{- end}}
+{{- if not .Follow}}
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+{{- end}}
+	response, err := client.Do(request)
+	if err != nil {
+		panic(err)
+	}
+	defer response.Body.Close()
+
+	responseBody, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(responseBody))
+}
+`
+
+const fetchTemplate = `{{- if .Ignored}}// curl options not translated: {{join .Ignored " "}}
+{{end -}}
+{{- if .Insecure}}// certificates can't be ignored by fetch, e.g. set NODE_TLS_REJECT_UNAUTHORIZED=0 in node
+{{end -}}
+const response = await fetch({{quote .Url}}, {
+  method: {{quote .Method}},
+{{- if or .Headers .User}}
+  headers: {
+{{- range .Headers}}
+    {{quote .Name}}: {{quote .Value}},
+{{- end}}
+{{- if .User}}
+    "Authorization": {{quote (basicAuth .User .Password)}},
+{{- end}}
+  },
+{{- end}}
+{{- if .Body}}
+  body: {{quote .Body}},
+{{- end}}
+{{- if not .Follow}}
+  redirect: "manual",
+{{- end}}
+{{- if .Timeout}}
+  signal: AbortSignal.timeout({{milliseconds .Timeout}}),
+{{- end}}
+});
+console.log(await response.text());
+`
+
+// showTemplates are the targets of show --as, curl is rendered by showCurlTemplate instead.
+var showTemplates = map[string]string{
+	"httpie":          httpieTemplate,
+	"wget":            wgetTemplate,
+	"powershell":      powershellTemplate,
+	"python-requests": pythonRequestsTemplate,
+	"go-nethttp":      goNetHttpTemplate,
+	"fetch":           fetchTemplate,
+}
+
+var snippetFunctions = template.FuncMap{
+	"join":  strings.Join,
+	"lower": strings.ToLower,
+	"shell": func(value string) string {
+		return "'" + strings.Replace(value, "'", "'\\''", -1) + "'"
+	},
+	"powershell": func(value string) string {
+		return "'" + strings.Replace(value, "'", "''", -1) + "'"
+	},
+	// a json string, valid in python and javascript too
+	"quote": func(value string) string {
+		buf := new(bytes.Buffer)
+		encoder := json.NewEncoder(buf)
+		encoder.SetEscapeHTML(false)
+		encoder.Encode(value)
+		return strings.TrimSuffix(buf.String(), "\n")
+	},
+	"goQuote": func(value string) string {
+		if strconv.CanBackquote(value) && strings.Contains(value, "\n") {
+			return "`" + value + "`"
+		}
+		return strconv.Quote(value)
+	},
+	"basicAuth": func(user string, password string) string {
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+password))
+	},
+	"ceil": func(seconds float64) int {
+		return int(seconds + 0.999)
+	},
+	"milliseconds": func(seconds float64) int {
+		return int(seconds * 1000)
+	},
+}
+
+// ShowTargets returns the names accepted by show --as.
+func ShowTargets() []string {
+	targets := []string{CURL_RUNNER}
+	for target := range showTemplates {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets[1:])
+	return targets
+}
+
+func checkShowTarget(target string) error {
+	if _, ok := showTemplates[target]; ok || target == "" || target == CURL_RUNNER {
+		return nil
+	}
+	return errors.New(fmt.Sprintf("Unknown target '%v', expected one of %v", target, strings.Join(ShowTargets(), ", ")))
+}
+
+func newSnippet(request *Request) (*snippet, error) {
+	settings, unsupported, err := parseHttpSettings(request)
+	if err != nil {
+		return nil, err
+	}
+	view := &snippet{
+		Method:   request.Method,
+		Url:      requestUrl(request),
+		Body:     request.Body,
+		Insecure: settings.insecure,
+		Follow:   settings.follow,
+		Timeout:  settings.timeout.Seconds(),
+		Ignored:  unsupported,
+	}
+	if settings.user != "" {
+		credentials := strings.SplitN(settings.user, ":", 2)
+		view.User = credentials[0]
+		if len(credentials) == 2 {
+			view.Password = credentials[1]
+		}
+	}
+	hasContentType := false
+	for _, header := range request.Headers {
+		i := strings.Index(header, ":")
+		if i <= 0 {
+			continue
+		}
+		name := strings.TrimSpace(header[:i])
+		hasContentType = hasContentType || strings.EqualFold(name, "Content-Type")
+		view.Headers = append(view.Headers, snippetHeader{Name: name, Value: strings.TrimSpace(header[i+1:])})
+	}
+	if request.Body != "" && !hasContentType {
+		// same as curl --data-binary
+		view.Headers = append(view.Headers, snippetHeader{Name: "Content-Type", Value: "application/x-www-form-urlencoded"})
+	}
+	return view, nil
+}
+
+// renderSnippet writes the request as the code of the target client.
+func renderSnippet(target string, request *Request) (string, error) {
+	view, err := newSnippet(request)
+	if err != nil {
+		return "", err
+	}
+	t := template.Must(template.New(target).Funcs(snippetFunctions).Parse(showTemplates[target]))
+	buf := new(bytes.Buffer)
+	if err := t.Execute(buf, view); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
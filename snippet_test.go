@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func snippetConfiguration(t *testing.T) *Configuration {
+	reader := &MockReader{configurations: make(map[string][]byte)}
+	reader.configurations["test"] = []byte(
+		`
+url: https://localhost
+
+headers:
+  - 'Accept: application/json'
+
+endpoints:
+  create:
+    path: /items/{id}
+    method: POST
+    query:
+      - q: 'a b'
+    headers:
+      - 'Content-Type: application/json'
+    options:
+      - '-u user:pass'
+      - '-L'
+      - '-m 2'
+      - '--verbose'
+    body:
+      name: "it's"
+  simple:
+    path: /items
+
+requests:
+  create_item:
+    endpoint: create
+    id: 3
+`)
+	conf, err := NewConfiguration(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conf
+}
+
+func TestShowAsHttpie(t *testing.T) {
+	var b bytes.Buffer
+	printer := &Printer{conf: snippetConfiguration(t), writer: &b, as: "httpie"}
+	printer.ShowRequestOrEndpoint("create_item")
+
+	expected := `# curl options not translated: --verbose
+http --follow --auth 'user:pass' --timeout=2 POST 'https://localhost/items/3?q=a+b' \
+    'Accept:application/json' \
+    'Content-Type:application/json' \
+    --raw '{"name":"it'\''s"}'
+`
+	if b.String() != expected {
+		t.Errorf("Unexpected httpie\n%v", b.String())
+	}
+}
+
+func TestShowAsPythonRequests(t *testing.T) {
+	var b bytes.Buffer
+	printer := &Printer{conf: snippetConfiguration(t), writer: &b, as: "python-requests"}
+	printer.ShowRequestOrEndpoint("simple")
+
+	expected := `import requests
+
+response = requests.request(
+    "GET",
+    "https://localhost/items",
+    headers={
+        "Accept": "application/json",
+    },
+    allow_redirects=False,
+)
+print(response.text)
+`
+	if b.String() != expected {
+		t.Errorf("Unexpected python\n%v", b.String())
+	}
+}
+
+func TestShowAsGoNetHttp(t *testing.T) {
+	conf := snippetConfiguration(t)
+	for _, name := range []string{"create_item", "simple"} {
+		var b bytes.Buffer
+		printer := &Printer{conf: conf, writer: &b, as: "go-nethttp"}
+		printer.ShowRequestOrEndpoint(name)
+
+		if _, err := parser.ParseFile(token.NewFileSet(), "main.go", b.String(), 0); err != nil {
+			t.Errorf("Invalid go code %v\n%v", err, b.String())
+		}
+	}
+}
+
+func TestShowAsAllTargets(t *testing.T) {
+	conf := snippetConfiguration(t)
+	for _, target := range ShowTargets()[1:] {
+		var b bytes.Buffer
+		printer := &Printer{conf: conf, writer: &b, as: target}
+		printer.ShowRequestOrEndpoint("create_item")
+
+		output := b.String()
+		if !strings.Contains(output, "localhost/items/3?q=a+b") || !strings.Contains(output, "POST") {
+			t.Errorf("Target %v missing url or method\n%v", target, output)
+		}
+		if !strings.Contains(output, "curl options not translated: --verbose") {
+			t.Errorf("Target %v missing ignored options\n%v", target, output)
+		}
+	}
+}
+
+func TestCheckShowTarget(t *testing.T) {
+	for _, target := range []string{"", "curl", "wget", "fetch"} {
+		if err := checkShowTarget(target); err != nil {
+			t.Error(err)
+		}
+	}
+	if err := checkShowTarget("telnet"); err == nil {
+		t.Error("Should fail on unknown target")
+	}
+}
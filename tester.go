@@ -0,0 +1,76 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// Tester runs requests and checks their responses against the request expectations.
+type Tester struct {
+	executor *Executor
+	writer   io.Writer
+}
+
+func NewTester(executor *Executor, writer io.Writer) *Tester {
+	return &Tester{executor: executor, writer: writer}
+}
+
+// Test runs the given requests, or every request with an expect block when none is given,
+// and returns how many of them failed.
+func (tester *Tester) Test(requestNames []string) (int, error) {
+	if len(requestNames) == 0 {
+		requestNames = tester.requestsWithExpectations()
+	}
+	if len(requestNames) == 0 {
+		return 0, errors.New("No request with expectations found")
+	}
+
+	failed := 0
+	for _, name := range requestNames {
+		request := tester.executor.conf.Requests[name]
+		if request == nil {
+			return failed, errors.New(fmt.Sprint("Could not find request ", name))
+		}
+		if !tester.testRequest(request) {
+			failed++
+		}
+	}
+	fmt.Fprintf(tester.writer, "\n%v passed, %v failed\n", len(requestNames)-failed, failed)
+	return failed, nil
+}
+
+func (tester *Tester) testRequest(request *Request) bool {
+	response, err := tester.executor.ExecuteRequest(request.Name, nil)
+	if err != nil {
+		fmt.Fprintf(tester.writer, "FAIL %v\n    %v\n", request.Name, err)
+		return false
+	}
+
+	var failures []string
+	if request.Expect != nil {
+		failures = request.Expect.Check(response)
+	}
+	result := "PASS"
+	if len(failures) > 0 {
+		result = "FAIL"
+	}
+	fmt.Fprintf(tester.writer, "%v %v (%v)\n", result, request.Name, response.Elapsed.Round(time.Millisecond))
+	for _, failure := range failures {
+		fmt.Fprintf(tester.writer, "    %v\n", failure)
+	}
+	return len(failures) == 0
+}
+
+func (tester *Tester) requestsWithExpectations() []string {
+	var names []string
+	for name, request := range tester.executor.conf.Requests {
+		if request.Expect != nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTestRequests(t *testing.T) {
+	reader := &MockReader{configurations: make(map[string][]byte)}
+	reader.configurations["test"] = []byte(
+		`
+url: local
+
+endpoints:
+  test:
+    path: /test
+
+requests:
+  passing:
+    endpoint: test
+    expect:
+      status: 200
+  failing:
+    endpoint: test
+    expect:
+      status: 201
+  no_expectations:
+    endpoint: test
+`)
+
+	conf, err := NewConfiguration(reader)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	runner := &MockResponseRunner{responses: map[string]*Response{
+		"passing": {StatusCode: 200},
+		"failing": {StatusCode: 200},
+	}}
+
+	var b bytes.Buffer
+	tester := NewTester(NewExecutor(conf, runner, &MockVariableReader{}), &b)
+	failed, err := tester.Test(nil)
+	if err != nil {
+		t.Error("Should not throw an error ", err)
+	}
+	if failed != 1 {
+		t.Errorf("Should be 1 failed request but got %v", failed)
+	}
+	if testOutput != strings.Trim(b.String(), " \n\t") {
+		t.Errorf("Test output doesn't look correct %v", b.String())
+	}
+	if len(runner.executed) != 2 {
+		t.Error("Should have executed only the requests with expectations")
+	}
+}
+
+func TestTestRequestNotFound(t *testing.T) {
+	conf, _ := NewConfiguration(NewSilentConfigurationReader("_resources/valid", "api-requests.yaml"))
+	tester := NewTester(NewExecutor(conf, &MockResponseRunner{}, &MockVariableReader{}), &bytes.Buffer{})
+
+	if _, err := tester.Test([]string{"not-found"}); err == nil || err.Error() != "Could not find request not-found" {
+		t.Error("Should have thrown a not found error but got ", err)
+	}
+}
+
+var testOutput = `FAIL failing (0s)
+    status 200, expected 201
+PASS passing (0s)
+
+1 passed, 1 failed`
+
+func (runner *MockResponseRunner) Run(request *Request, command []string) (*Response, error) {
+	runner.executed = append(runner.executed, request)
+	if response, ok := runner.responses[request.Name]; ok {
+		return response, nil
+	}
+	return &Response{StatusCode: 200}, nil
+}
+
+type MockResponseRunner struct {
+	responses map[string]*Response
+	executed  []*Request
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// SESSION_FILE is where the session variables are kept, relative to the yaml directory.
+const SESSION_FILE = ".gohit/session.json"
+
+// VariableStore keeps the variables set while running requests, like the values
+// captured from responses. When it has a file the variables survive between runs.
+type VariableStore struct {
+	mutex     sync.RWMutex
+	file      string
+	variables map[string]string
+	changed   bool
+}
+
+func NewVariableStore() *VariableStore {
+	return &VariableStore{variables: make(map[string]string)}
+}
+
+// NewFileVariableStore loads the variables saved in file, if any.
+func NewFileVariableStore(file string) (*VariableStore, error) {
+	store := NewVariableStore()
+	store.file = file
+	source, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return store, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(source, &store.variables); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+func (store *VariableStore) Get(name string) (string, bool) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+	value, ok := store.variables[name]
+	return value, ok
+}
+
+func (store *VariableStore) Set(name string, value string) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	store.variables[name] = value
+	store.changed = true
+}
+
+func (store *VariableStore) Names() []string {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+	names := make([]string, 0, len(store.variables))
+	for name := range store.variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Without returns a copy of the store, without a file, leaving out the given names.
+func (store *VariableStore) Without(names map[string]string) *VariableStore {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+	copied := NewVariableStore()
+	for name, value := range store.variables {
+		if _, ok := names[name]; !ok {
+			copied.variables[name] = value
+		}
+	}
+	return copied
+}
+
+// Clear removes every variable, and the store file.
+func (store *VariableStore) Clear() error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	store.variables = make(map[string]string)
+	store.changed = false
+	if store.file == "" {
+		return nil
+	}
+	if err := os.Remove(store.file); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Save writes the variables to the store file when they have changed.
+func (store *VariableStore) Save() error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	if store.file == "" || !store.changed {
+		return nil
+	}
+	asJson, err := json.MarshalIndent(store.variables, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(store.file), 0700); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(store.file, asJson, 0600); err != nil {
+		return err
+	}
+	store.changed = false
+	return nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/smallfish/simpleyaml"
+	"io/ioutil"
+	"strings"
+)
+
+// ReadVariables reads the variables given on the command line, from a yaml file with
+// one variable per key and from name=value assignments, which take precedence.
+func ReadVariables(assignments []string, file string) (map[string]interface{}, error) {
+	variables := make(map[string]interface{})
+	if file != "" {
+		source, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		yaml, err := simpleyaml.NewYaml(source)
+		if err != nil {
+			return nil, err
+		}
+		asMap, err := yaml.Map()
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("Variables file '%v' must be a map", file))
+		}
+		for name, value := range asMap {
+			variables[fmt.Sprint(name)] = value
+		}
+	}
+
+	for _, assignment := range assignments {
+		i := strings.Index(assignment, "=")
+		if i <= 0 {
+			return nil, errors.New(fmt.Sprintf("Invalid variable '%v', expected name=value", assignment))
+		}
+		variables[assignment[:i]] = assignment[i+1:]
+	}
+	return variables, nil
+}
+
+// SetVariables sets variables taking precedence over the request, endpoint and global
+// ones, creating the requests again to use them.
+func (conf *Configuration) SetVariables(variables map[string]interface{}) error {
+	conf.Variables = variables
+	conf.Requests = make(map[string]*Request)
+	return conf.loadRequests()
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestReadVariables(t *testing.T) {
+	variables, err := ReadVariables([]string{"name=cli", "query=a=b"}, "_resources/valid/vars.yaml")
+	if err != nil {
+		t.Errorf("Should not throw an error '%v'", err)
+		return
+	}
+
+	if len(variables) != 4 ||
+		variables["name"] != "cli" ||
+		variables["date"] != "yesterday" ||
+		variables["version"] != 17 ||
+		variables["query"] != "a=b" {
+		t.Errorf("Variables problem %v", variables)
+	}
+}
+
+func TestReadInvalidVariable(t *testing.T) {
+	if _, err := ReadVariables([]string{"name"}, ""); err == nil || err.Error() != "Invalid variable 'name', expected name=value" {
+		t.Error("Should have thrown an invalid variable error but got ", err)
+	}
+}
+
+func TestVariablesPrecedence(t *testing.T) {
+	conf, err := NewConfiguration(NewSilentConfigurationReader("_resources/valid", "api-requests.yaml"))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := conf.SetVariables(map[string]interface{}{"name": "cli", "version": 17, "variable": "other"}); err != nil {
+		t.Error(err)
+		return
+	}
+
+	request4 := conf.Requests["request4"]
+	if request4.Path != "/path4/other" || request4.QueryRaw != "name=cli&date=today&version=17" {
+		t.Errorf("Request4 configuration problem %v", request4)
+	}
+
+	request41 := conf.Requests["request4_1"]
+	if request41.QueryRaw != "name=cli&date=today1&version=17" {
+		t.Errorf("Request4_1 configuration problem %v", request41)
+	}
+}